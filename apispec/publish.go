@@ -0,0 +1,60 @@
+package apispec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WithServerURL returns the YAML document with its servers list replaced by a single
+// entry pointing at serverURL. Key order and comments are preserved.
+func WithServerURL(data []byte, serverURL string) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("apispec: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("apispec: document is not a mapping")
+	}
+	doc := root.Content[0]
+
+	var servers yaml.Node
+	if err := servers.Encode([]Server{{URL: serverURL, Description: "API server"}}); err != nil {
+		return nil, err
+	}
+
+	replaced := false
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == "servers" {
+			doc.Content[i+1] = &servers
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "servers"}
+		doc.Content = append(doc.Content, key, &servers)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ToJSON converts a YAML document to its JSON representation.
+func ToJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("apispec: %w", err)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
@@ -0,0 +1,226 @@
+package apispec
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldError describes a single schema violation at a location such as "body.data.id".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ValidateValue checks a value decoded by encoding/json against schema.
+func (d *Document) ValidateValue(schema *Schema, value interface{}, field string) []FieldError {
+	var errs []FieldError
+	d.validate(schema, value, field, &errs)
+	return errs
+}
+
+func (d *Document) validate(schema *Schema, value interface{}, field string, errs *[]FieldError) {
+	s, err := d.resolve(schema)
+	if err != nil {
+		*errs = append(*errs, FieldError{Field: field, Message: err.Error()})
+		return
+	}
+	if s == nil {
+		return
+	}
+
+	for _, sub := range s.AllOf {
+		d.validate(sub, value, field, errs)
+	}
+	if len(s.OneOf) > 0 {
+		matched := 0
+		for _, sub := range s.OneOf {
+			var subErrs []FieldError
+			d.validate(sub, value, field, &subErrs)
+			if len(subErrs) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf("must match exactly one schema, matched %d", matched)})
+		}
+	}
+
+	if value == nil {
+		if s.Type != "" && !s.Nullable {
+			*errs = append(*errs, FieldError{Field: field, Message: "must not be null"})
+		}
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf("must be one of %v", s.Enum)})
+	}
+
+	typ := s.Type
+	if _, isObj := value.(map[string]interface{}); typ == "" && isObj && (len(s.Properties) > 0 || len(s.Required) > 0) {
+		// allOf fragments often omit "type: object"
+		typ = "object"
+	}
+
+	switch typ {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			*errs = append(*errs, FieldError{Field: field, Message: "must be an object"})
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				*errs = append(*errs, FieldError{Field: join(field, name), Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if v, ok := obj[name]; ok {
+				d.validate(s.Properties[name], v, join(field, name), errs)
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			*errs = append(*errs, FieldError{Field: field, Message: "must be an array"})
+			return
+		}
+		for i, item := range arr {
+			d.validate(s.Items, item, field+"["+strconv.Itoa(i)+"]", errs)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			*errs = append(*errs, FieldError{Field: field, Message: "must be a string"})
+			return
+		}
+		d.validateString(s, str, field, errs)
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			*errs = append(*errs, FieldError{Field: field, Message: "must be an integer"})
+			return
+		}
+		validateRange(s, n, field, errs)
+	case "number":
+		n, ok := value.(float64)
+		if !ok {
+			*errs = append(*errs, FieldError{Field: field, Message: "must be a number"})
+			return
+		}
+		validateRange(s, n, field, errs)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			*errs = append(*errs, FieldError{Field: field, Message: "must be a boolean"})
+		}
+	}
+}
+
+func (d *Document) validateString(s *Schema, str, field string, errs *[]FieldError) {
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf("must be at least %d characters", *s.MinLength)})
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf("must be at most %d characters", *s.MaxLength)})
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			*errs = append(*errs, FieldError{Field: field, Message: "invalid pattern in spec: " + err.Error()})
+		} else if !re.MatchString(str) {
+			*errs = append(*errs, FieldError{Field: field, Message: "must match pattern " + s.Pattern})
+		}
+	}
+	switch s.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+			*errs = append(*errs, FieldError{Field: field, Message: "must be an RFC 3339 date-time"})
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", str); err != nil {
+			*errs = append(*errs, FieldError{Field: field, Message: "must be a date (YYYY-MM-DD)"})
+		}
+	case "email":
+		if _, err := mail.ParseAddress(str); err != nil {
+			*errs = append(*errs, FieldError{Field: field, Message: "must be an email address"})
+		}
+	}
+}
+
+func validateRange(s *Schema, n float64, field string, errs *[]FieldError) {
+	if s.Minimum != nil && n < *s.Minimum {
+		*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf("must be >= %v", *s.Minimum)})
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf("must be <= %v", *s.Maximum)})
+	}
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		// YAML decodes integers as int, JSON as float64
+		if i, ok := e.(int); ok {
+			e = float64(i)
+		}
+		if reflect.DeepEqual(e, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// coerce converts a raw path/query string into the JSON type the schema expects.
+func (d *Document) coerce(schema *Schema, raw string) (interface{}, error) {
+	s, err := d.resolve(schema)
+	if err != nil || s == nil {
+		return raw, err
+	}
+	switch s.Type {
+	case "integer":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return float64(n), nil
+	case "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return b, nil
+	}
+	return raw, nil
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
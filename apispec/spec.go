@@ -0,0 +1,167 @@
+package apispec
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is the subset of an OpenAPI 3.0 document the validator understands.
+type Document struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Servers    []Server             `yaml:"servers"`
+	Paths      map[string]*PathItem `yaml:"paths"`
+	Components Components           `yaml:"components"`
+}
+
+type Server struct {
+	URL         string `yaml:"url"`
+	Description string `yaml:"description"`
+}
+
+type Components struct {
+	Schemas   map[string]*Schema   `yaml:"schemas"`
+	Responses map[string]*Response `yaml:"responses"`
+}
+
+type PathItem struct {
+	Parameters []Parameter `yaml:"parameters"`
+	Get        *Operation  `yaml:"get"`
+	Put        *Operation  `yaml:"put"`
+	Post       *Operation  `yaml:"post"`
+	Delete     *Operation  `yaml:"delete"`
+	Patch      *Operation  `yaml:"patch"`
+}
+
+type Operation struct {
+	Summary     string               `yaml:"summary"`
+	Tags        []string             `yaml:"tags"`
+	Parameters  []Parameter          `yaml:"parameters"`
+	RequestBody *RequestBody         `yaml:"requestBody"`
+	Responses   map[string]*Response `yaml:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Schema   *Schema `yaml:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `yaml:"required"`
+	Content  map[string]*MediaType `yaml:"content"`
+}
+
+type Response struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Content     map[string]*MediaType `yaml:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `yaml:"schema"`
+}
+
+type Schema struct {
+	Ref        string             `yaml:"$ref"`
+	Type       string             `yaml:"type"`
+	Format     string             `yaml:"format"`
+	Enum       []interface{}      `yaml:"enum"`
+	Required   []string           `yaml:"required"`
+	Properties map[string]*Schema `yaml:"properties"`
+	Items      *Schema            `yaml:"items"`
+	AllOf      []*Schema          `yaml:"allOf"`
+	OneOf      []*Schema          `yaml:"oneOf"`
+	Nullable   bool               `yaml:"nullable"`
+	Minimum    *float64           `yaml:"minimum"`
+	Maximum    *float64           `yaml:"maximum"`
+	MinLength  *int               `yaml:"minLength"`
+	MaxLength  *int               `yaml:"maxLength"`
+	Pattern    string             `yaml:"pattern"`
+}
+
+// Parse decodes a YAML (or JSON) OpenAPI document.
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("apispec: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("apispec: unsupported openapi version %q", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// LoadFile reads and parses the document at path.
+func LoadFile(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// BasePath returns the path component of the first server URL, e.g. "/api".
+func (d *Document) BasePath() string {
+	if len(d.Servers) == 0 {
+		return ""
+	}
+	u, err := url.Parse(d.Servers[0].URL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// Operation returns the operation declared for method, or nil.
+func (p *PathItem) Operation(method string) *Operation {
+	switch strings.ToUpper(method) {
+	case "GET":
+		return p.Get
+	case "PUT":
+		return p.Put
+	case "POST":
+		return p.Post
+	case "DELETE":
+		return p.Delete
+	case "PATCH":
+		return p.Patch
+	}
+	return nil
+}
+
+// resolveResponse follows a local "#/components/responses/..." reference.
+func (d *Document) resolveResponse(r *Response) (*Response, error) {
+	if r == nil || r.Ref == "" {
+		return r, nil
+	}
+	name := strings.TrimPrefix(r.Ref, "#/components/responses/")
+	target, ok := d.Components.Responses[name]
+	if name == r.Ref || !ok {
+		return nil, fmt.Errorf("unknown response %s", r.Ref)
+	}
+	return target, nil
+}
+
+// resolve follows a local "#/components/schemas/..." reference.
+func (d *Document) resolve(s *Schema) (*Schema, error) {
+	for depth := 0; s != nil && s.Ref != ""; depth++ {
+		if depth > 32 {
+			return nil, fmt.Errorf("reference cycle at %s", s.Ref)
+		}
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		if name == s.Ref {
+			return nil, fmt.Errorf("unsupported reference %s", s.Ref)
+		}
+		target, ok := d.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown schema %s", s.Ref)
+		}
+		s = target
+	}
+	return s, nil
+}
@@ -0,0 +1,346 @@
+package apispec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"Go-PetStoreApp/logx"
+)
+
+// Violation is a request or response that did not conform to the spec.
+type Violation struct {
+	Method string       `json:"method"`
+	Path   string       `json:"path"`
+	Route  string       `json:"route,omitempty"`
+	Status int          `json:"status,omitempty"`
+	Phase  string       `json:"phase"` // "request" or "response"
+	Errors []FieldError `json:"errors"`
+}
+
+func (v Violation) String() string {
+	msgs := make([]string, 0, len(v.Errors))
+	for _, e := range v.Errors {
+		msgs = append(msgs, e.Error())
+	}
+	return fmt.Sprintf("%s %s %s (%d): %s", v.Phase, v.Method, v.Path, v.Status, strings.Join(msgs, "; "))
+}
+
+// Validator matches HTTP traffic to spec operations and checks it against their schemas.
+type Validator struct {
+	Doc      *Document
+	basePath string
+	routes   []route
+
+	mu         sync.Mutex
+	violations []Violation
+}
+
+type route struct {
+	template string
+	segments []string
+	item     *PathItem
+}
+
+// NewValidator builds a validator for doc. Paths are matched below the first server URL's path.
+func NewValidator(doc *Document) *Validator {
+	v := &Validator{Doc: doc, basePath: doc.BasePath()}
+	for template, item := range doc.Paths {
+		v.routes = append(v.routes, route{
+			template: template,
+			segments: strings.Split(strings.Trim(template, "/"), "/"),
+			item:     item,
+		})
+	}
+	// static segments win over parameters, e.g. /users/register before /users/{id}
+	sort.Slice(v.routes, func(i, j int) bool {
+		a, b := v.routes[i], v.routes[j]
+		if len(a.segments) != len(b.segments) {
+			return len(a.segments) < len(b.segments)
+		}
+		for k := range a.segments {
+			pa, pb := isParam(a.segments[k]), isParam(b.segments[k])
+			if pa != pb {
+				return !pa
+			}
+		}
+		return a.template < b.template
+	})
+	return v
+}
+
+// Match finds the operation for method and a request path such as "/api/pets/1".
+// ok is false when the path lies outside the spec's base path or is not declared.
+func (v *Validator) Match(method, path string) (template string, op *Operation, item *PathItem, params map[string]string, ok bool) {
+	if !strings.HasPrefix(path, v.basePath+"/") {
+		return "", nil, nil, nil, false
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(path, v.basePath), "/"), "/")
+	for _, rt := range v.routes {
+		if len(rt.segments) != len(parts) {
+			continue
+		}
+		params = map[string]string{}
+		matched := true
+		for i, seg := range rt.segments {
+			if isParam(seg) {
+				params[strings.Trim(seg, "{}")] = parts[i]
+			} else if seg != parts[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return rt.template, rt.item.Operation(method), rt.item, params, true
+		}
+	}
+	return "", nil, nil, nil, false
+}
+
+// Covers reports whether path is below the spec's base path, i.e. whether it should be documented.
+func (v *Validator) Covers(path string) bool {
+	return strings.HasPrefix(path, v.basePath+"/")
+}
+
+// ValidateRequest checks path parameters, query parameters and the JSON body of r.
+// body is the already-read request body.
+func (v *Validator) ValidateRequest(r *http.Request, body []byte) []FieldError {
+	template, op, item, pathParams, ok := v.Match(r.Method, r.URL.Path)
+	if !ok {
+		return []FieldError{{Message: "undocumented path " + r.URL.Path}}
+	}
+	if op == nil {
+		return []FieldError{{Message: fmt.Sprintf("method %s not documented for %s", r.Method, template)}}
+	}
+
+	var errs []FieldError
+	query := r.URL.Query()
+	for _, p := range mergeParameters(item.Parameters, op.Parameters) {
+		var raw string
+		var present bool
+		switch p.In {
+		case "path":
+			raw, present = pathParams[p.Name]
+		case "query":
+			present = query.Has(p.Name)
+			raw = query.Get(p.Name)
+		case "header":
+			raw = r.Header.Get(p.Name)
+			present = raw != ""
+		default:
+			continue
+		}
+		field := p.In + "." + p.Name
+		if !present {
+			if p.Required {
+				errs = append(errs, FieldError{Field: field, Message: "is required"})
+			}
+			continue
+		}
+		value, err := v.Doc.coerce(p.Schema, raw)
+		if err != nil {
+			errs = append(errs, FieldError{Field: field, Message: err.Error()})
+			continue
+		}
+		errs = append(errs, v.Doc.ValidateValue(p.Schema, value, field)...)
+	}
+
+	if op.RequestBody == nil {
+		return errs
+	}
+	if StreamedBody(r) {
+		if _, ok := op.RequestBody.Content[mediaTypeOf(r)]; !ok {
+			errs = append(errs, FieldError{Field: "body", Message: "unsupported content type " + r.Header.Get("Content-Type")})
+		}
+		return errs
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			errs = append(errs, FieldError{Field: "body", Message: "is required"})
+		}
+		return errs
+	}
+	media, ok := mediaFor(op.RequestBody.Content, r.Header.Get("Content-Type"))
+	if !ok {
+		return append(errs, FieldError{Field: "body", Message: "unsupported content type " + r.Header.Get("Content-Type")})
+	}
+	return append(errs, v.validateJSON(media, body, "body")...)
+}
+
+// ValidateResponse checks a recorded response for the request method and path.
+func (v *Validator) ValidateResponse(method, path string, status int, header http.Header, body []byte) []FieldError {
+	template, op, _, _, ok := v.Match(method, path)
+	if !ok || op == nil {
+		return []FieldError{{Message: fmt.Sprintf("undocumented operation %s %s", method, path)}}
+	}
+	resp, err := v.Doc.resolveResponse(lookupResponse(op.Responses, status))
+	if err != nil {
+		return []FieldError{{Message: err.Error()}}
+	}
+	if resp == nil {
+		return []FieldError{{Message: fmt.Sprintf("status %d not documented for %s %s", status, method, template)}}
+	}
+	if len(resp.Content) == 0 {
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return []FieldError{{Field: "body", Message: "is empty but the spec declares content"}}
+	}
+	media, ok := mediaFor(resp.Content, header.Get("Content-Type"))
+	if !ok {
+		return []FieldError{{Field: "body", Message: "undocumented content type " + header.Get("Content-Type")}}
+	}
+	return v.validateJSON(media, body, "body")
+}
+
+func (v *Validator) validateJSON(media *MediaType, body []byte, field string) []FieldError {
+	if media == nil || media.Schema == nil {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []FieldError{{Field: field, Message: "invalid JSON: " + err.Error()}}
+	}
+	return v.Doc.ValidateValue(media.Schema, value, field)
+}
+
+// Middleware validates every request and response under the spec's base path and records
+// violations. When logViolations is set each violation is also logged at Warn with the
+// request's logger (see logx.FromContext), which carries the request ID.
+// Traffic is never rejected; see Violations for asserting on the results.
+func (v *Validator) Middleware(next http.Handler, logViolations bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !v.Covers(r.URL.Path) || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var body []byte
+		if !StreamedBody(r) {
+			body, _ = io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		template, _, _, _, _ := v.Match(r.Method, r.URL.Path)
+		if errs := v.ValidateRequest(r, body); len(errs) > 0 {
+			v.record(r.Context(), Violation{Method: r.Method, Path: r.URL.Path, Route: template, Phase: "request", Errors: errs}, logViolations)
+		}
+
+		rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if errs := v.ValidateResponse(r.Method, r.URL.Path, rec.status, rec.Header(), rec.body.Bytes()); len(errs) > 0 {
+			v.record(r.Context(), Violation{Method: r.Method, Path: r.URL.Path, Route: template, Status: rec.status, Phase: "response", Errors: errs}, logViolations)
+		}
+	})
+}
+
+func (v *Validator) record(ctx context.Context, violation Violation, logIt bool) {
+	v.mu.Lock()
+	v.violations = append(v.violations, violation)
+	v.mu.Unlock()
+	if logIt {
+		errs := make([]string, 0, len(violation.Errors))
+		for _, e := range violation.Errors {
+			errs = append(errs, e.Error())
+		}
+		attrs := []any{"phase", violation.Phase, "route", violation.Route, "errors", errs}
+		if violation.Status != 0 {
+			attrs = append(attrs, "status", violation.Status)
+		}
+		logx.FromContext(ctx).Warn("apispec violation", attrs...)
+	}
+}
+
+// Violations returns a copy of everything recorded since the last Reset.
+func (v *Validator) Violations() []Violation {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]Violation(nil), v.violations...)
+}
+
+// Reset discards recorded violations.
+func (v *Validator) Reset() {
+	v.mu.Lock()
+	v.violations = nil
+	v.mu.Unlock()
+}
+
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// mergeParameters lets operation-level parameters override path-level ones with the same name and location.
+func mergeParameters(pathLevel, opLevel []Parameter) []Parameter {
+	out := append([]Parameter(nil), opLevel...)
+	for _, p := range pathLevel {
+		overridden := false
+		for _, o := range opLevel {
+			if o.Name == p.Name && o.In == p.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func lookupResponse(responses map[string]*Response, status int) *Response {
+	if r, ok := responses[strconv.Itoa(status)]; ok {
+		return r
+	}
+	if r, ok := responses[strconv.Itoa(status/100)+"XX"]; ok {
+		return r
+	}
+	return responses["default"]
+}
+
+// StreamedBody reports whether a request carries a multipart body, such as a file upload.
+// Such bodies are left to the handler to read and check, and are not buffered or validated
+// against the spec.
+func StreamedBody(r *http.Request) bool {
+	return strings.HasPrefix(mediaTypeOf(r), "multipart/")
+}
+
+func mediaTypeOf(r *http.Request) string {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType
+}
+
+func mediaFor(content map[string]*MediaType, contentType string) (*MediaType, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "" {
+		// clients frequently omit Content-Type on JSON bodies; fall back to JSON
+		mediaType = "application/json"
+	}
+	if m, ok := content[mediaType]; ok {
+		return m, true
+	}
+	return nil, false
+}
@@ -0,0 +1,85 @@
+// Package apptest runs the application's router over the real services, for tests of the
+// HTTP API and of the client that need no database. The repositories keep their rows in a
+// Store in memory instead of Postgres, the search engine is search.Memory, and photos are
+// kept in memory too.
+package apptest
+
+import (
+	"Go-PetStoreApp/app"
+	"Go-PetStoreApp/controller"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/middleware"
+	"Go-PetStoreApp/model/web"
+	"Go-PetStoreApp/search"
+	"Go-PetStoreApp/service"
+	"context"
+
+	"github.com/go-playground/validator"
+	"github.com/julienschmidt/httprouter"
+)
+
+// PhotoLimits are the upload limits of the photo service and controller.
+var PhotoLimits = service.PhotoLimits{MaxBytes: 1 << 20, MaxPixels: 1 << 20, MaxPerPet: 5}
+
+// Services are the services behind a router; Spec is the OpenAPI document the router
+// publishes.
+type Services struct {
+	Spec     []byte
+	JWT      *helper.JWT
+	Store    *Store
+	Users    service.UserService
+	Pets     service.PetService
+	Taxonomy service.TaxonomyService
+	Photos   service.PetPhotoService
+	Audit    service.AuditService
+}
+
+func NewServices(spec []byte, jwtSecret string) *Services {
+	jwt := helper.NewJWT(jwtSecret, 1)
+	store := NewStore()
+	db := store.DB()
+	validate := validator.New()
+	engine := search.NewMemory()
+	blobs := &blobStore{objects: map[string][]byte{}}
+	users, pets, photos := userRepository{store}, petRepository{store}, photoRepository{store}
+	taxonomy, audits := taxonomyRepository{store}, auditRepository{store}
+	return &Services{
+		Spec:     spec,
+		JWT:      jwt,
+		Store:    store,
+		Users:    service.NewUserService(users, pets, audits, db, validate, jwt, engine),
+		Pets:     service.NewPetService(pets, users, taxonomy, photos, audits, db, validate, engine, blobs),
+		Taxonomy: service.NewTaxonomyService(taxonomy, audits, db, validate),
+		Photos:   service.NewPetPhotoService(pets, photos, audits, blobs, db, validate, PhotoLimits),
+		Audit:    service.NewAuditService(audits, pets, db, validate),
+	}
+}
+
+// AddUser registers a user with role "user" or "admin", as the admin commands do, and
+// returns them with a token.
+func (s *Services) AddUser(username, email, password, role string) web.AuthResponse {
+	resp, err := s.Users.Register(context.Background(), web.UserRegisterRequest{Username: username, Email: email, Password: password, Role: role})
+	helper.PanicIfError(err)
+	return resp
+}
+
+// Router wires the real controllers and middleware to s, without rate limits. The JWT
+// middleware checks revocations with revocations, or with s.Users if it is nil.
+func (s *Services) Router(revocations middleware.TokenRevocations) *httprouter.Router {
+	if revocations == nil {
+		revocations = s.Users
+	}
+	return app.NewRouter(
+		controller.NewUserController(s.Users),
+		controller.NewPetController(s.Pets, helper.NewCursors("", "cursor secret")),
+		controller.NewTaxonomyController(s.Taxonomy),
+		controller.NewPetPhotoController(s.Photos, PhotoLimits.MaxBytes),
+		controller.NewAuditController(s.Audit),
+		nil,
+		controller.NewDocsController(s.Spec, ""),
+		controller.NewHealthController(nil, s.JWT),
+		controller.NewLogController(),
+		middleware.NewJWTMiddleware(s.JWT, revocations),
+		nil,
+	)
+}
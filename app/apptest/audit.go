@@ -0,0 +1,36 @@
+package apptest
+
+import (
+	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/repository"
+	"context"
+	"database/sql"
+	"slices"
+)
+
+// auditRepository is an in-memory repository.AuditRepository.
+type auditRepository struct{ store *Store }
+
+func (r auditRepository) Create(ctx context.Context, tx *sql.Tx, entry domain.AuditEntry) {
+	r.store.lastAudit++
+	entry.ID = r.store.lastAudit
+	r.store.audit = append(r.store.audit, entry)
+}
+
+func (r auditRepository) FindPage(ctx context.Context, tx *sql.Tx, q repository.AuditQuery) ([]domain.AuditEntry, int) {
+	var entries []domain.AuditEntry
+	for _, e := range slices.Backward(r.store.audit) {
+		if (q.ActorID == 0 || e.ActorID == q.ActorID) &&
+			(q.Action == "" || e.Action == q.Action) &&
+			(q.Entity == "" || e.Entity == q.Entity) &&
+			(q.EntityID == 0 || e.EntityID == q.EntityID) &&
+			(q.RequestID == "" || e.RequestID == q.RequestID) &&
+			(q.From.IsZero() || !e.OccurredAt.Before(q.From)) &&
+			(q.Before.IsZero() || e.OccurredAt.Before(q.Before)) {
+			entries = append(entries, e)
+		}
+	}
+	total := len(entries)
+	entries = entries[min(q.Offset, total):]
+	return entries[:min(q.Limit, len(entries))], total
+}
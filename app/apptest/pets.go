@@ -0,0 +1,317 @@
+package apptest
+
+import (
+	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/repository"
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// petRepository is an in-memory repository.PetRepository.
+type petRepository struct{ store *Store }
+
+// checkMicrochip panics, like the unique index on live microchips fails the statement,
+// if another live pet has pet's microchip.
+func (r petRepository) checkMicrochip(pet domain.Pet) {
+	if pet.Microchip == "" {
+		return
+	}
+	for _, p := range r.store.pets {
+		if p.ID != pet.ID && p.DeletedAt.IsZero() && p.Microchip == pet.Microchip {
+			panic(fmt.Sprintf("duplicate microchip %s", pet.Microchip))
+		}
+	}
+}
+
+func (r petRepository) Create(ctx context.Context, tx *sql.Tx, pet domain.Pet) domain.Pet {
+	r.checkMicrochip(pet)
+	r.store.lastPet++
+	pet.ID, pet.Version = r.store.lastPet, 1
+	r.store.pets[pet.ID] = pet
+	return pet
+}
+
+func (r petRepository) find(id int, deleted bool) (domain.Pet, error) {
+	p, ok := r.store.pets[id]
+	if !ok || p.DeletedAt.IsZero() == deleted {
+		return domain.Pet{}, sql.ErrNoRows
+	}
+	return p, nil
+}
+
+func (r petRepository) FindById(ctx context.Context, tx *sql.Tx, id int) (domain.Pet, error) {
+	return r.find(id, false)
+}
+
+func (r petRepository) FindByMicrochip(ctx context.Context, tx *sql.Tx, microchip string) (domain.Pet, error) {
+	for _, p := range sorted(r.store.pets) {
+		if p.DeletedAt.IsZero() && p.Microchip == microchip {
+			return p, nil
+		}
+	}
+	return domain.Pet{}, sql.ErrNoRows
+}
+
+func (r petRepository) FindPage(ctx context.Context, tx *sql.Tx, q repository.PetQuery) ([]domain.Pet, int) {
+	var pets []domain.Pet
+	for _, p := range r.store.pets {
+		if matches(p, q) {
+			pets = append(pets, p)
+		}
+	}
+	total := -1
+	if q.CountTotal {
+		total = len(pets)
+	}
+
+	// as in the SQL, going backwards sorts the other way and is flipped at the end
+	sorts := keysetSorts(q.Sort)
+	backward := q.Before != nil
+	key := q.After
+	if backward {
+		key = q.Before
+	}
+	order := func(a, b domain.Pet) int {
+		for _, s := range sorts {
+			c := compareField(s.Field, a, b)
+			if s.Desc != backward {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+	slices.SortFunc(pets, order)
+	if key != nil {
+		keyPet, err := keyPet(sorts, key)
+		if err != nil {
+			panic(err)
+		}
+		pets = slices.DeleteFunc(pets, func(p domain.Pet) bool { return order(p, keyPet) <= 0 })
+	}
+	pets = pets[min(q.Offset, len(pets)):]
+	pets = pets[:min(q.Limit, len(pets))]
+	if backward {
+		slices.Reverse(pets)
+	}
+	return pets, total
+}
+
+// matches reports whether p passes the filters of q.
+func matches(p domain.Pet, q repository.PetQuery) bool {
+	contains := func(value, part string) bool {
+		return strings.Contains(strings.ToLower(value), strings.ToLower(part))
+	}
+	inRange := func(v float64, ok bool, lo, hi *float64) bool {
+		return (lo == nil || ok && v >= *lo) && (hi == nil || ok && v <= *hi)
+	}
+	inTimes := func(t time.Time, from, before time.Time) bool {
+		return (from.IsZero() || !t.IsZero() && !t.Before(from)) && (before.IsZero() || !t.IsZero() && t.Before(before))
+	}
+	return p.DeletedAt.IsZero() != q.Deleted &&
+		(q.OwnerID == 0 || p.CreatedBy == q.OwnerID) &&
+		(len(q.Species) == 0 || slices.Contains(q.Species, p.Species)) &&
+		(q.Name == "" || contains(p.Name, q.Name)) &&
+		(q.Color == "" || p.Color != "" && contains(p.Color, q.Color)) &&
+		(q.Sex == "" || p.Sex == q.Sex) &&
+		(q.Microchip == "" || p.Microchip == q.Microchip) &&
+		(q.Neutered == nil || p.Neutered != nil && *p.Neutered == *q.Neutered) &&
+		inRange(p.Price, true, q.MinPrice, q.MaxPrice) &&
+		inRange(weightKg(p), p.Weight > 0, q.MinWeightKg, q.MaxWeightKg) &&
+		inTimes(p.CreatedAt, q.CreatedFrom, q.CreatedBefore) &&
+		inTimes(p.UpdatedAt, q.UpdatedFrom, q.UpdatedBefore) &&
+		inTimes(p.DateOfBirth, dateOnly(q.BornFrom), dateOnly(q.BornBefore))
+}
+
+// weightKg is the weight_kg column of the schema.
+func weightKg(p domain.Pet) float64 {
+	switch p.WeightUnit {
+	case "g":
+		return p.Weight / 1000
+	case "lb":
+		return p.Weight * 0.45359237
+	case "oz":
+		return p.Weight * 0.028349523125
+	default:
+		return p.Weight
+	}
+}
+
+// dateOnly drops the time of day, as the listing compares dates of birth as dates.
+func dateOnly(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// keysetSorts appends id as the final tie-breaker, as the repository does.
+func keysetSorts(sorts []repository.PetSort) []repository.PetSort {
+	for _, s := range sorts {
+		if s.Field == "id" {
+			return sorts
+		}
+	}
+	return append(slices.Clone(sorts), repository.PetSort{Field: "id", Desc: true})
+}
+
+func compareField(field string, a, b domain.Pet) int {
+	switch field {
+	case "id":
+		return cmp.Compare(a.ID, b.ID)
+	case "name":
+		return strings.Compare(a.Name, b.Name)
+	case "species":
+		return strings.Compare(a.Species, b.Species)
+	case "price":
+		return cmp.Compare(a.Price, b.Price)
+	case "created_at":
+		return a.CreatedAt.Compare(b.CreatedAt)
+	case "updated_at":
+		return a.UpdatedAt.Compare(b.UpdatedAt)
+	}
+	panic("cannot sort by " + field)
+}
+
+// keyPet is a pet with the sort fields of a keyset key (see repository.PetKey), to compare
+// the listed pets with.
+func keyPet(sorts []repository.PetSort, key []string) (domain.Pet, error) {
+	if len(key) != len(sorts) {
+		return domain.Pet{}, fmt.Errorf("keyset key has %d values for %d sort fields", len(key), len(sorts))
+	}
+	var p domain.Pet
+	for i, s := range sorts {
+		var err error
+		switch s.Field {
+		case "id":
+			p.ID, err = strconv.Atoi(key[i])
+		case "name":
+			p.Name = key[i]
+		case "species":
+			p.Species = key[i]
+		case "price":
+			p.Price, err = strconv.ParseFloat(key[i], 64)
+		case "created_at":
+			p.CreatedAt, err = time.Parse(time.RFC3339Nano, key[i])
+		case "updated_at":
+			p.UpdatedAt, err = time.Parse(time.RFC3339Nano, key[i])
+		}
+		if err != nil {
+			return domain.Pet{}, fmt.Errorf("keyset key %s: %w", s.Field, err)
+		}
+	}
+	return p, nil
+}
+
+func (r petRepository) Update(ctx context.Context, tx *sql.Tx, pet domain.Pet) (domain.Pet, bool) {
+	current, err := r.find(pet.ID, false)
+	if err != nil || current.Version != pet.Version {
+		return pet, false
+	}
+	r.checkMicrochip(pet)
+	pet.CreatedBy, pet.CreatedAt = current.CreatedBy, current.CreatedAt
+	pet.Version++
+	r.store.pets[pet.ID] = pet
+	return pet, true
+}
+
+func (r petRepository) Touch(ctx context.Context, tx *sql.Tx, id int) {
+	if p, ok := r.store.pets[id]; ok {
+		p.Version++
+		r.store.pets[id] = p
+	}
+}
+
+func (r petRepository) Delete(ctx context.Context, tx *sql.Tx, id int, at time.Time) {
+	if p, err := r.find(id, false); err == nil {
+		p.DeletedAt = at
+		p.Version++
+		r.store.pets[id] = p
+	}
+}
+
+// updateWhere applies fn to the pets matching, in ID order, and returns their IDs.
+func (r petRepository) updateWhere(match func(domain.Pet) bool, fn func(p *domain.Pet)) []int {
+	var ids []int
+	for _, p := range sorted(r.store.pets) {
+		if match(p) {
+			fn(&p)
+			p.Version++
+			r.store.pets[p.ID] = p
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids
+}
+
+func (r petRepository) DeleteByOwner(ctx context.Context, tx *sql.Tx, ownerID int, at time.Time) []int {
+	return r.updateWhere(func(p domain.Pet) bool { return p.CreatedBy == ownerID && p.DeletedAt.IsZero() },
+		func(p *domain.Pet) { p.DeletedAt = at })
+}
+
+func (r petRepository) FindDeletedById(ctx context.Context, tx *sql.Tx, id int) (domain.Pet, error) {
+	return r.find(id, true)
+}
+
+func (r petRepository) Restore(ctx context.Context, tx *sql.Tx, id int) bool {
+	p, err := r.find(id, true)
+	if owner, ok := r.store.users[p.CreatedBy]; err != nil || !ok || !owner.DeletedAt.IsZero() {
+		return false
+	}
+	p.DeletedAt = time.Time{}
+	p.Version++
+	r.store.pets[id] = p
+	return true
+}
+
+func (r petRepository) RestoreByOwner(ctx context.Context, tx *sql.Tx, ownerID int, deletedAt time.Time) []int {
+	return r.updateWhere(func(p domain.Pet) bool {
+		if p.CreatedBy != ownerID || !p.DeletedAt.Equal(deletedAt) {
+			return false
+		}
+		_, err := r.FindByMicrochip(ctx, tx, p.Microchip)
+		return p.Microchip == "" || err != nil
+	}, func(p *domain.Pet) { p.DeletedAt = time.Time{} })
+}
+
+func (r petRepository) FindExpired(ctx context.Context, tx *sql.Tx, deletedBefore time.Time, limit int) []int {
+	var ids []int
+	for _, p := range sorted(r.store.pets) {
+		owner := r.store.users[p.CreatedBy]
+		expired := func(at time.Time) bool { return !at.IsZero() && at.Before(deletedBefore) }
+		if len(ids) < limit && (expired(p.DeletedAt) || expired(owner.DeletedAt)) {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids
+}
+
+func (r petRepository) Purge(ctx context.Context, tx *sql.Tx, ids []int) {
+	for _, id := range ids {
+		delete(r.store.pets, id)
+	}
+	for id, photo := range r.store.photos {
+		if slices.Contains(ids, photo.PetID) {
+			delete(r.store.photos, id)
+		}
+	}
+}
+
+func (r petRepository) ReassignOwner(ctx context.Context, tx *sql.Tx, fromUserID, toUserID int) []int {
+	return r.updateWhere(func(p domain.Pet) bool { return p.CreatedBy == fromUserID && p.DeletedAt.IsZero() },
+		func(p *domain.Pet) { p.CreatedBy = toUserID })
+}
+
+func (r petRepository) DeleteAll(ctx context.Context, tx *sql.Tx) {
+	clear(r.store.pets)
+	clear(r.store.photos)
+	r.store.lastPet = 0
+}
@@ -0,0 +1,118 @@
+package apptest
+
+import (
+	"Go-PetStoreApp/blob"
+	"Go-PetStoreApp/model/domain"
+	"bytes"
+	"cmp"
+	"context"
+	"database/sql"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// photoRepository is an in-memory repository.PetPhotoRepository.
+type photoRepository struct{ store *Store }
+
+// Lock needs no lock: the transaction holds the whole store.
+func (r photoRepository) Lock(ctx context.Context, tx *sql.Tx, petID int) {}
+
+func (r photoRepository) Create(ctx context.Context, tx *sql.Tx, photo domain.PetPhoto) domain.PetPhoto {
+	photo.Position = len(r.FindByPet(ctx, tx, photo.PetID)) + 1
+	r.store.lastPhoto++
+	photo.ID = r.store.lastPhoto
+	r.store.photos[photo.ID] = photo
+	return photo
+}
+
+func (r photoRepository) FindById(ctx context.Context, tx *sql.Tx, petID, id int) (domain.PetPhoto, error) {
+	photo, ok := r.store.photos[id]
+	if !ok || photo.PetID != petID {
+		return domain.PetPhoto{}, sql.ErrNoRows
+	}
+	return photo, nil
+}
+
+func (r photoRepository) FindByPet(ctx context.Context, tx *sql.Tx, petID int) []domain.PetPhoto {
+	return r.FindByPets(ctx, tx, []int{petID})[petID]
+}
+
+func (r photoRepository) FindByPets(ctx context.Context, tx *sql.Tx, petIDs []int) map[int][]domain.PetPhoto {
+	photos := map[int][]domain.PetPhoto{}
+	for _, photo := range r.FindAll(ctx, tx) {
+		if slices.Contains(petIDs, photo.PetID) {
+			photos[photo.PetID] = append(photos[photo.PetID], photo)
+		}
+	}
+	return photos
+}
+
+func (r photoRepository) FindAll(ctx context.Context, tx *sql.Tx) []domain.PetPhoto {
+	photos := slices.Collect(maps.Values(r.store.photos))
+	slices.SortFunc(photos, func(a, b domain.PetPhoto) int {
+		return cmp.Or(cmp.Compare(a.PetID, b.PetID), cmp.Compare(a.Position, b.Position))
+	})
+	return photos
+}
+
+func (r photoRepository) SetPrimary(ctx context.Context, tx *sql.Tx, petID, photoID int) {
+	for _, photo := range r.FindByPet(ctx, tx, petID) {
+		photo.Primary = photo.ID == photoID
+		r.store.photos[photo.ID] = photo
+	}
+}
+
+func (r photoRepository) SetOrder(ctx context.Context, tx *sql.Tx, petID int, photoIDs []int) {
+	for i, id := range photoIDs {
+		if photo, ok := r.store.photos[id]; ok && photo.PetID == petID {
+			photo.Position = i + 1
+			r.store.photos[id] = photo
+		}
+	}
+}
+
+func (r photoRepository) Delete(ctx context.Context, tx *sql.Tx, photo domain.PetPhoto) {
+	delete(r.store.photos, photo.ID)
+	for _, p := range r.FindByPet(ctx, tx, photo.PetID) {
+		if p.Position > photo.Position {
+			p.Position--
+			r.store.photos[p.ID] = p
+		}
+	}
+}
+
+// blobStore is an in-memory blob.Store; its URLs point nowhere.
+type blobStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *blobStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = slices.Clone(data)
+	return nil
+}
+
+func (s *blobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, blob.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *blobStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *blobStore) URL(key string) string {
+	return "/media/" + key
+}
@@ -0,0 +1,133 @@
+package apptest
+
+import (
+	"Go-PetStoreApp/model/domain"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Store holds the rows of the in-memory repositories. Its DB runs one transaction at a
+// time: Begin locks the store and saves a copy of the rows, which Rollback puts back. The
+// repositories do not use their *sql.Tx; they may only be called inside a transaction.
+type Store struct {
+	mu    sync.Mutex
+	saved *tables
+	tables
+
+	// the last IDs given out; like Postgres sequences they are not rolled back
+	lastUser, lastPet, lastPhoto, lastSpecies, lastBreed int
+	lastAudit                                            int64
+}
+
+// tables are the rows, by ID.
+type tables struct {
+	users       map[int]domain.User
+	revocations map[int]time.Time // by user ID
+	pets        map[int]domain.Pet
+	photos      map[int]domain.PetPhoto
+	species     map[int]domain.Species
+	breeds      map[int]domain.Breed
+	audit       []domain.AuditEntry
+}
+
+func NewStore() *Store {
+	return &Store{tables: tables{
+		users:       map[int]domain.User{},
+		revocations: map[int]time.Time{},
+		pets:        map[int]domain.Pet{},
+		photos:      map[int]domain.PetPhoto{},
+		species:     map[int]domain.Species{},
+		breeds:      map[int]domain.Breed{},
+	}}
+}
+
+func (t tables) clone() *tables {
+	return &tables{
+		users:       maps.Clone(t.users),
+		revocations: maps.Clone(t.revocations),
+		pets:        maps.Clone(t.pets),
+		photos:      maps.Clone(t.photos),
+		species:     maps.Clone(t.species),
+		breeds:      maps.Clone(t.breeds),
+		audit:       slices.Clone(t.audit),
+	}
+}
+
+// sorted returns the rows in ID order.
+func sorted[T any](rows map[int]T) []T {
+	ids := slices.Sorted(maps.Keys(rows))
+	list := make([]T, len(ids))
+	for i, id := range ids {
+		list[i] = rows[id]
+	}
+	return list
+}
+
+// DB returns a database whose transactions run on the store.
+func (s *Store) DB() *sql.DB {
+	return sql.OpenDB(connector{s})
+}
+
+type connector struct{ store *Store }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return conn(c), nil }
+
+func (c connector) Driver() driver.Driver { return storeDriver{} }
+
+type storeDriver struct{}
+
+func (storeDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("apptest: open the database with Store.DB")
+}
+
+// conn accepts any statement and does nothing; helper.BeginTx tags the connection with one.
+type conn struct{ store *Store }
+
+func (c conn) Prepare(string) (driver.Stmt, error) { return stmt{}, nil }
+
+func (c conn) Close() error { return nil }
+
+func (c conn) Begin() (driver.Tx, error) {
+	c.store.mu.Lock()
+	c.store.saved = c.store.tables.clone()
+	return tx(c), nil
+}
+
+type tx struct{ store *Store }
+
+func (t tx) Commit() error {
+	t.store.saved = nil
+	t.store.mu.Unlock()
+	return nil
+}
+
+func (t tx) Rollback() error {
+	t.store.tables, t.store.saved = *t.store.saved, nil
+	t.store.mu.Unlock()
+	return nil
+}
+
+type stmt struct{}
+
+func (stmt) Close() error { return nil }
+
+func (stmt) NumInput() int { return -1 }
+
+func (stmt) Exec([]driver.Value) (driver.Result, error) { return driver.ResultNoRows, nil }
+
+func (stmt) Query([]driver.Value) (driver.Rows, error) { return rows{}, nil }
+
+type rows struct{}
+
+func (rows) Columns() []string { return nil }
+
+func (rows) Close() error { return nil }
+
+func (rows) Next([]driver.Value) error { return io.EOF }
@@ -0,0 +1,174 @@
+package apptest
+
+import (
+	"Go-PetStoreApp/model/domain"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// taxonomyRepository is an in-memory repository.TaxonomyRepository. Renames cascade to
+// pets and breeds, and deletes of entries in use fail, as the foreign keys do.
+type taxonomyRepository struct{ store *Store }
+
+func (r taxonomyRepository) FindAllSpecies(ctx context.Context, tx *sql.Tx) []domain.Species {
+	species := sorted(r.store.species)
+	slices.SortStableFunc(species, func(a, b domain.Species) int { return strings.Compare(a.Name, b.Name) })
+	return species
+}
+
+func (r taxonomyRepository) FindSpeciesById(ctx context.Context, tx *sql.Tx, id int) (domain.Species, error) {
+	s, ok := r.store.species[id]
+	if !ok {
+		return domain.Species{}, sql.ErrNoRows
+	}
+	return s, nil
+}
+
+func (r taxonomyRepository) FindSpeciesByName(ctx context.Context, tx *sql.Tx, name string) (domain.Species, error) {
+	for _, s := range sorted(r.store.species) {
+		if strings.EqualFold(s.Name, name) {
+			return s, nil
+		}
+	}
+	return domain.Species{}, sql.ErrNoRows
+}
+
+func (r taxonomyRepository) CreateSpecies(ctx context.Context, tx *sql.Tx, species domain.Species) domain.Species {
+	if _, err := r.FindSpeciesByName(ctx, tx, species.Name); err == nil {
+		panic(fmt.Sprintf("duplicate species %s", species.Name))
+	}
+	r.store.lastSpecies++
+	species.ID = r.store.lastSpecies
+	r.store.species[species.ID] = species
+	return species
+}
+
+func (r taxonomyRepository) UpdateSpecies(ctx context.Context, tx *sql.Tx, species domain.Species) domain.Species {
+	old, ok := r.store.species[species.ID]
+	if !ok {
+		return species
+	}
+	for id, p := range r.store.pets {
+		if p.Species == old.Name {
+			p.Species = species.Name
+			r.store.pets[id] = p
+		}
+	}
+	for id, b := range r.store.breeds {
+		if b.Species == old.Name {
+			b.Species = species.Name
+			r.store.breeds[id] = b
+		}
+	}
+	old.Name, old.UpdatedAt = species.Name, species.UpdatedAt
+	r.store.species[species.ID] = old
+	return species
+}
+
+func (r taxonomyRepository) DeleteSpecies(ctx context.Context, tx *sql.Tx, id int) {
+	if s, ok := r.store.species[id]; ok {
+		if pets, breeds := r.CountSpeciesUsage(ctx, tx, s.Name); pets+breeds > 0 {
+			panic(fmt.Sprintf("species %s is in use", s.Name))
+		}
+	}
+	delete(r.store.species, id)
+}
+
+func (r taxonomyRepository) FindBreedsBySpecies(ctx context.Context, tx *sql.Tx, species string) []domain.Breed {
+	var breeds []domain.Breed
+	for _, b := range sorted(r.store.breeds) {
+		if b.Species == species {
+			breeds = append(breeds, b)
+		}
+	}
+	slices.SortStableFunc(breeds, func(a, b domain.Breed) int { return strings.Compare(a.Name, b.Name) })
+	return breeds
+}
+
+func (r taxonomyRepository) FindBreedById(ctx context.Context, tx *sql.Tx, id int) (domain.Breed, error) {
+	b, ok := r.store.breeds[id]
+	if !ok {
+		return domain.Breed{}, sql.ErrNoRows
+	}
+	return b, nil
+}
+
+func (r taxonomyRepository) FindBreedByName(ctx context.Context, tx *sql.Tx, species, name string) (domain.Breed, error) {
+	for _, b := range sorted(r.store.breeds) {
+		if b.Species == species && strings.EqualFold(b.Name, name) {
+			return b, nil
+		}
+	}
+	return domain.Breed{}, sql.ErrNoRows
+}
+
+func (r taxonomyRepository) CreateBreed(ctx context.Context, tx *sql.Tx, breed domain.Breed) domain.Breed {
+	if _, err := r.FindSpeciesByName(ctx, tx, breed.Species); err != nil {
+		panic(fmt.Sprintf("unknown species %s", breed.Species))
+	}
+	if _, err := r.FindBreedByName(ctx, tx, breed.Species, breed.Name); err == nil {
+		panic(fmt.Sprintf("duplicate breed %s", breed.Name))
+	}
+	r.store.lastBreed++
+	breed.ID = r.store.lastBreed
+	r.store.breeds[breed.ID] = breed
+	return breed
+}
+
+func (r taxonomyRepository) UpdateBreed(ctx context.Context, tx *sql.Tx, breed domain.Breed) domain.Breed {
+	old, ok := r.store.breeds[breed.ID]
+	if !ok {
+		return breed
+	}
+	for _, id := range r.FindBreedPets(ctx, tx, old.Species, old.Name) {
+		p := r.store.pets[id]
+		p.Breed = breed.Name
+		r.store.pets[id] = p
+	}
+	old.Name, old.UpdatedAt = breed.Name, breed.UpdatedAt
+	r.store.breeds[breed.ID] = old
+	return breed
+}
+
+func (r taxonomyRepository) DeleteBreed(ctx context.Context, tx *sql.Tx, id int) {
+	if b, ok := r.store.breeds[id]; ok && r.CountBreedUsage(ctx, tx, b.Species, b.Name) > 0 {
+		panic(fmt.Sprintf("breed %s is in use", b.Name))
+	}
+	delete(r.store.breeds, id)
+}
+
+func (r taxonomyRepository) CountSpeciesUsage(ctx context.Context, tx *sql.Tx, species string) (int, int) {
+	var breeds int
+	for _, b := range r.store.breeds {
+		if b.Species == species {
+			breeds++
+		}
+	}
+	return len(r.FindSpeciesPets(ctx, tx, species)), breeds
+}
+
+func (r taxonomyRepository) CountBreedUsage(ctx context.Context, tx *sql.Tx, species, breed string) int {
+	return len(r.FindBreedPets(ctx, tx, species, breed))
+}
+
+func (r taxonomyRepository) FindSpeciesPets(ctx context.Context, tx *sql.Tx, species string) []int {
+	return r.petIDs(func(p domain.Pet) bool { return p.Species == species })
+}
+
+func (r taxonomyRepository) FindBreedPets(ctx context.Context, tx *sql.Tx, species, breed string) []int {
+	return r.petIDs(func(p domain.Pet) bool { return p.Species == species && p.Breed == breed })
+}
+
+// petIDs returns the IDs of the pets matching, deleted ones too, in ID order.
+func (r taxonomyRepository) petIDs(match func(domain.Pet) bool) []int {
+	var ids []int
+	for _, p := range sorted(r.store.pets) {
+		if match(p) {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids
+}
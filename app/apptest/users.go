@@ -0,0 +1,183 @@
+package apptest
+
+import (
+	"Go-PetStoreApp/model/domain"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+)
+
+// userRepository is an in-memory repository.UserRepository.
+type userRepository struct{ store *Store }
+
+// find returns the live user matching, or sql.ErrNoRows.
+func (r userRepository) find(match func(domain.User) bool) (domain.User, error) {
+	for _, u := range sorted(r.store.users) {
+		if u.DeletedAt.IsZero() && match(u) {
+			return u, nil
+		}
+	}
+	return domain.User{}, sql.ErrNoRows
+}
+
+// change applies fn to the live user id at version, as an UPDATE ... WHERE version would.
+func (r userRepository) change(id, version int, fn func(u *domain.User)) (domain.User, error) {
+	u, ok := r.store.users[id]
+	if !ok || !u.DeletedAt.IsZero() || u.Version != version {
+		return domain.User{}, sql.ErrNoRows
+	}
+	fn(&u)
+	u.Version++
+	r.store.users[id] = u
+	return u, nil
+}
+
+func (r userRepository) Create(ctx context.Context, tx *sql.Tx, user domain.User) (domain.User, error) {
+	if _, err := r.find(func(u domain.User) bool { return u.Username == user.Username || u.Email == user.Email }); err == nil {
+		return domain.User{}, errors.New("duplicate username or email")
+	}
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	r.store.lastUser++
+	user.ID, user.Version = r.store.lastUser, 1
+	r.store.users[user.ID] = user
+	return user, nil
+}
+
+func (r userRepository) FindByEmail(ctx context.Context, tx *sql.Tx, email string) (domain.User, error) {
+	return r.find(func(u domain.User) bool { return u.Email == email })
+}
+
+func (r userRepository) FindByUsername(ctx context.Context, tx *sql.Tx, username string) (domain.User, error) {
+	return r.find(func(u domain.User) bool { return u.Username == username })
+}
+
+func (r userRepository) UsernameExists(ctx context.Context, tx *sql.Tx, username string) (bool, error) {
+	for _, u := range r.store.users {
+		if u.Username == username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r userRepository) FindById(ctx context.Context, tx *sql.Tx, id int) (domain.User, error) {
+	return r.find(func(u domain.User) bool { return u.ID == id })
+}
+
+// Lock needs no lock: the transaction holds the whole store.
+func (r userRepository) Lock(ctx context.Context, tx *sql.Tx, id int) (domain.User, error) {
+	return r.FindById(ctx, tx, id)
+}
+
+func (r userRepository) FindAll(ctx context.Context, tx *sql.Tx) ([]domain.User, error) {
+	var users []domain.User
+	for _, u := range sorted(r.store.users) {
+		if u.DeletedAt.IsZero() {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (r userRepository) Update(ctx context.Context, tx *sql.Tx, user domain.User) (domain.User, error) {
+	return r.change(user.ID, user.Version, func(u *domain.User) {
+		u.Username, u.Email, u.UpdatedAt = user.Username, user.Email, user.UpdatedAt
+	})
+}
+
+func (r userRepository) Delete(ctx context.Context, tx *sql.Tx, id int, at time.Time) error {
+	if u, ok := r.store.users[id]; ok && u.DeletedAt.IsZero() {
+		u.DeletedAt = at
+		u.Version++
+		r.store.users[id] = u
+	}
+	return nil
+}
+
+func (r userRepository) FindDeleted(ctx context.Context, tx *sql.Tx) ([]domain.User, error) {
+	var users []domain.User
+	for _, u := range sorted(r.store.users) {
+		if !u.DeletedAt.IsZero() {
+			users = append(users, u)
+		}
+	}
+	slices.SortStableFunc(users, func(a, b domain.User) int {
+		if c := b.DeletedAt.Compare(a.DeletedAt); c != 0 {
+			return c
+		}
+		return b.ID - a.ID
+	})
+	return users, nil
+}
+
+func (r userRepository) FindDeletedById(ctx context.Context, tx *sql.Tx, id int) (domain.User, error) {
+	u, ok := r.store.users[id]
+	if !ok || u.DeletedAt.IsZero() {
+		return domain.User{}, sql.ErrNoRows
+	}
+	return u, nil
+}
+
+func (r userRepository) Restore(ctx context.Context, tx *sql.Tx, id int) error {
+	if u, ok := r.store.users[id]; ok && !u.DeletedAt.IsZero() {
+		u.DeletedAt = time.Time{}
+		u.Version++
+		r.store.users[id] = u
+	}
+	return nil
+}
+
+func (r userRepository) Purge(ctx context.Context, tx *sql.Tx, deletedBefore time.Time) ([]int, error) {
+	var ids []int
+	for _, u := range sorted(r.store.users) {
+		if u.DeletedAt.IsZero() || !u.DeletedAt.Before(deletedBefore) || r.ownsPets(u.ID) {
+			continue
+		}
+		delete(r.store.users, u.ID)
+		delete(r.store.revocations, u.ID)
+		ids = append(ids, u.ID)
+	}
+	return ids, nil
+}
+
+func (r userRepository) ownsPets(id int) bool {
+	for _, p := range r.store.pets {
+		if p.CreatedBy == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (r userRepository) UpdatePassword(ctx context.Context, tx *sql.Tx, id, version int, passwordHash string) error {
+	_, err := r.change(id, version, func(u *domain.User) { u.PasswordHash = passwordHash })
+	return err
+}
+
+func (r userRepository) UpdateRole(ctx context.Context, tx *sql.Tx, id, version int, role string) error {
+	_, err := r.change(id, version, func(u *domain.User) { u.Role = role })
+	return err
+}
+
+func (r userRepository) RevokeTokens(ctx context.Context, tx *sql.Tx, id int, before time.Time) error {
+	r.store.revocations[id] = before
+	return nil
+}
+
+func (r userRepository) TokensRevokedBefore(ctx context.Context, tx *sql.Tx, id int) (time.Time, error) {
+	return r.store.revocations[id], nil
+}
+
+func (r userRepository) DeleteAll(ctx context.Context, tx *sql.Tx) error {
+	if len(r.store.pets) > 0 {
+		return errors.New("users still own pets")
+	}
+	clear(r.store.users)
+	clear(r.store.revocations)
+	r.store.lastUser = 0
+	return nil
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+
+	"Go-PetStoreApp/blob"
+)
+
+// MediaPath is where the local blob backend's files are served.
+const MediaPath = "/media"
+
+// NewBlobStore opens the configured photo storage. For the local backend it also returns
+// the handler that serves the files under MediaPath; for s3 the handler is nil.
+func NewBlobStore(cfg *Config) (blob.Store, http.Handler, error) {
+	if strings.EqualFold(cfg.BlobBackend, "s3") {
+		store, err := blob.NewS3(blob.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+			PublicURL: cfg.BlobPublicURL,
+		})
+		return store, nil, err
+	}
+	publicURL := cfg.BlobPublicURL
+	if publicURL == "" {
+		publicURL = strings.TrimRight(cfg.PublicURL, "/") + MediaPath
+	}
+	store, err := blob.NewLocal(cfg.BlobLocalDir, publicURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, http.StripPrefix(MediaPath, store.Handler()), nil
+}
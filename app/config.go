@@ -1,50 +1,223 @@
 package app
 
 import (
-	"log"
+	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
+	"Go-PetStoreApp/ratelimit"
 )
 
+// Config is the effective configuration. Each field is named by its `config` key, which is
+// the environment variable, the lower-cased key in the config file ("db_host") and the flag
+// with dashes ("--db-host"). Secrets can be read from the file named by KEY_FILE.
 type Config struct {
-	DBHost       string
-	DBPort       string
-	DBUser       string
-	DBPassword   string
-	DBName       string
-	DBSSLMode    string
-	JWTSecretKey string
-	TokenExpiry  int // hours
+	DBHost       string `config:"DB_HOST" help:"PostgreSQL host"`
+	DBPort       string `config:"DB_PORT" default:"5432" help:"PostgreSQL port"`
+	DBUser       string `config:"DB_USER" help:"PostgreSQL user"`
+	DBPassword   string `config:"DB_PASSWORD" secret:"true" help:"PostgreSQL password"`
+	DBName       string `config:"DB_NAME" help:"PostgreSQL database"`
+	DBSSLMode    string `config:"DB_SSLMODE" default:"require" help:"disable|allow|prefer|require|verify-ca|verify-full"`
+	JWTSecretKey string `config:"JWT_SECRET_KEY" secret:"true" help:"HMAC key for signing access tokens"`
+	TokenExpiry  int    `config:"TOKEN_EXPIRATION_HOURS" default:"24" help:"access token lifetime in hours"`
+
+	// CursorSecretKey signs pagination cursors; derived from JWTSecretKey when empty.
+	CursorSecretKey string `config:"CURSOR_SECRET_KEY" secret:"true" help:"HMAC key for pagination cursors (default: derived from JWT_SECRET_KEY)"`
+
+	// PublicURL is the externally visible origin (and optional path prefix) of the API,
+	// e.g. "https://example.com/petstore". Used for the servers entry of the published spec.
+	PublicURL string `config:"PUBLIC_BASE_URL" default:"http://localhost:3000" help:"externally visible base URL"`
+
+	// APISpecValidation checks traffic against apispec.yaml and logs violations (dev only).
+	APISpecValidation bool `config:"APISPEC_VALIDATE" default:"false" help:"log responses that violate apispec.yaml"`
+
+	// Logging: LogFormat is "text" or "json"; LogLevel is debug|info|warn|error.
+	LogFormat string `config:"LOG_FORMAT" default:"text" help:"text|json"`
+	LogLevel  string `config:"LOG_LEVEL" default:"info" help:"debug|info|warn|error"`
+
+	// HTTP server
+	ServerAddr        string        `config:"SERVER_ADDR" default:":3000" help:"listen address; a bare :port listens on all interfaces"`
+	ReadTimeout       time.Duration `config:"SERVER_READ_TIMEOUT" default:"15s"`
+	ReadHeaderTimeout time.Duration `config:"SERVER_READ_HEADER_TIMEOUT" default:"5s"`
+	WriteTimeout      time.Duration `config:"SERVER_WRITE_TIMEOUT" default:"30s"`
+	IdleTimeout       time.Duration `config:"SERVER_IDLE_TIMEOUT" default:"120s"`
+	ShutdownTimeout   time.Duration `config:"SERVER_SHUTDOWN_TIMEOUT" default:"20s" help:"how long in-flight requests get to finish on SIGINT/SIGTERM"`
+	MaxHeaderBytes    int           `config:"SERVER_MAX_HEADER_BYTES" default:"1048576"`
+
+	// ShutdownDrainDelay keeps serving with /readyz failing before the listener closes.
+	ShutdownDrainDelay time.Duration `config:"SERVER_DRAIN_DELAY" default:"5s" help:"keep serving with /readyz failing before closing the listener"`
+
+	// AutoMigrate applies pending migrations at startup.
+	AutoMigrate bool `config:"DB_AUTO_MIGRATE" default:"true" help:"apply pending migrations at startup"`
+
+	// TLS is enabled when both files are set; they are re-read when changed on disk.
+	TLSCertFile string `config:"TLS_CERT_FILE" help:"PEM certificate; enables TLS together with --tls-key-file"`
+	TLSKeyFile  string `config:"TLS_KEY_FILE" help:"PEM private key"`
+
+	// Tracing: TracingExporter is none|stdout|otlp. Spans go to TracingFile (default stdout)
+	// for the stdout exporter and to TracingOTLPEndpoint over OTLP/HTTP for otlp.
+	TracingExporter     string  `config:"TRACING_EXPORTER" default:"none" help:"none|stdout|otlp"`
+	TracingFile         string  `config:"TRACING_FILE" help:"file for the stdout exporter"`
+	TracingOTLPEndpoint string  `config:"TRACING_OTLP_ENDPOINT" help:"OTLP/HTTP collector host:port"`
+	TracingOTLPInsecure bool    `config:"TRACING_OTLP_INSECURE" default:"false" help:"use plain HTTP for OTLP"`
+	TracingSampleRatio  float64 `config:"TRACING_SAMPLE_RATIO" default:"1" help:"fraction of new traces recorded"`
+
+	// SearchBackend is postgres (full-text search over the pets table) or memory (an
+	// in-process index loaded at startup; for development only).
+	SearchBackend string `config:"SEARCH_BACKEND" default:"postgres" help:"postgres|memory"`
+
+	// Photo storage: BlobBackend is local (files below BlobLocalDir, served at /media/) or
+	// s3 (any S3-compatible service; point S3Endpoint at e.g. MinIO to try it locally).
+	// BlobPublicURL overrides where clients download photos from.
+	BlobBackend   string `config:"BLOB_BACKEND" default:"local" help:"local|s3"`
+	BlobLocalDir  string `config:"BLOB_LOCAL_DIR" default:"data/media" help:"directory of the local backend"`
+	BlobPublicURL string `config:"BLOB_PUBLIC_URL" help:"base URL of photo files (default: PUBLIC_BASE_URL/media, or the bucket URL)"`
+	S3Endpoint    string `config:"S3_ENDPOINT" help:"S3-compatible endpoint, e.g. https://s3.eu-west-1.amazonaws.com or http://localhost:9000"`
+	S3Region      string `config:"S3_REGION" default:"us-east-1"`
+	S3Bucket      string `config:"S3_BUCKET"`
+	S3AccessKey   string `config:"S3_ACCESS_KEY"`
+	S3SecretKey   string `config:"S3_SECRET_KEY" secret:"true"`
+	S3PathStyle   bool   `config:"S3_PATH_STYLE" default:"true" help:"address objects as endpoint/bucket/key rather than bucket.endpoint/key"`
+
+	// Photo upload limits
+	PhotoMaxBytes  int `config:"PHOTO_MAX_BYTES" default:"10485760" help:"largest accepted photo file"`
+	PhotoMaxPixels int `config:"PHOTO_MAX_PIXELS" default:"25000000" help:"largest accepted photo, in pixels"`
+	PhotoMaxPerPet int `config:"PHOTO_MAX_PER_PET" default:"20"`
+
+	// Soft delete: deleted users and pets can be restored for SoftDeleteRetention; a purge
+	// every PurgeInterval (0 disables it) then removes them for good.
+	SoftDeleteRetention time.Duration `config:"SOFT_DELETE_RETENTION" default:"720h" help:"how long deleted users and pets stay restorable"`
+	PurgeInterval       time.Duration `config:"PURGE_INTERVAL" default:"1h" help:"how often expired deleted records are purged; 0 disables"`
+
+	// Rate limiting: RateLimitPolicies is a comma-separated list of pattern=limit/period
+	// (see ratelimit.ParsePolicies). RateLimitTrustProxy keys anonymous clients by X-Forwarded-For
+	// and also makes it the IP recorded in the audit log. RateLimitAPIKeys lists name=key API
+	// clients, which send the key as X-API-Key and are limited per key.
+	RateLimitEnabled    bool   `config:"RATE_LIMIT_ENABLED" default:"true"`
+	RateLimitPolicies   string `config:"RATE_LIMIT_POLICIES" default:"POST /api/users/login=10/1m, POST /api/users/register=5/1m, POST /api/auth/refresh=30/1m, /api/*=300/1m"`
+	RateLimitTrustProxy bool   `config:"RATE_LIMIT_TRUST_PROXY" default:"false"`
+	RateLimitAPIKeys    string `config:"RATE_LIMIT_API_KEYS" secret:"true" help:"comma-separated name=key API clients, limited per key"`
+
+	// CORS: origins are exact or wildcard-subdomain ("https://*.example.com") patterns.
+	CORSAllowedOrigins   []string      `config:"CORS_ALLOWED_ORIGINS" default:"http://localhost:5173"`
+	CORSAllowedMethods   []string      `config:"CORS_ALLOWED_METHODS" default:"GET, POST, PUT, PATCH, DELETE"`
+	CORSAllowedHeaders   []string      `config:"CORS_ALLOWED_HEADERS" default:"Content-Type, Authorization, X-API-Key, X-Request-ID, If-Match, If-None-Match, traceparent, tracestate"`
+	CORSExposedHeaders   []string      `config:"CORS_EXPOSED_HEADERS" default:"X-Request-ID, ETag, traceparent, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After"`
+	CORSMaxAge           time.Duration `config:"CORS_MAX_AGE" default:"10m"`
+	CORSAllowCredentials bool          `config:"CORS_ALLOW_CREDENTIALS" default:"true"`
 }
 
-// LoadConfig loads .env and env vars; fails fast if JWT secret missing.
-func LoadConfig() *Config {
-	_ = godotenv.Load()
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
 
-	expiry := 24
-	if s := os.Getenv("TOKEN_EXPIRATION_HOURS"); s != "" {
-		if v, err := strconv.Atoi(s); err == nil && v > 0 {
-			expiry = v
-		} else {
-			log.Println("invalid TOKEN_EXPIRATION_HOURS, defaulting to 24")
+// Validate returns every problem with the configuration, or nil.
+func (c *Config) Validate() []string {
+	var problems []string
+	add := func(format string, args ...any) { problems = append(problems, fmt.Sprintf(format, args...)) }
+	oneOf := func(key, value string, allowed ...string) {
+		for _, a := range allowed {
+			if strings.EqualFold(value, a) {
+				return
+			}
 		}
+		add("%s: %q is not one of %s", key, value, strings.Join(allowed, ", "))
 	}
 
-	secret := os.Getenv("JWT_SECRET_KEY")
-	if secret == "" {
-		log.Fatal("JWT_SECRET_KEY must be set")
+	for key, value := range map[string]string{"DB_HOST": c.DBHost, "DB_USER": c.DBUser, "DB_NAME": c.DBName, "JWT_SECRET_KEY": c.JWTSecretKey} {
+		if value == "" {
+			add("%s: required", key)
+		}
+	}
+	if port, err := strconv.Atoi(c.DBPort); err != nil || port < 1 || port > 65535 {
+		add("DB_PORT: %q is not a valid port", c.DBPort)
+	}
+	oneOf("DB_SSLMODE", c.DBSSLMode, sslModes...)
+	if c.TokenExpiry <= 0 {
+		add("TOKEN_EXPIRATION_HOURS: must be positive")
 	}
+	if u, err := url.Parse(c.PublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		add("PUBLIC_BASE_URL: %q is not an absolute http(s) URL", c.PublicURL)
+	}
+
+	oneOf("LOG_FORMAT", c.LogFormat, "text", "json")
+	oneOf("LOG_LEVEL", c.LogLevel, "debug", "info", "warn", "error")
 
-	return &Config{
-		DBHost:       os.Getenv("DB_HOST"),
-		DBPort:       os.Getenv("DB_PORT"),
-		DBUser:       os.Getenv("DB_USER"),
-		DBPassword:   os.Getenv("DB_PASSWORD"),
-		DBName:       os.Getenv("DB_NAME"),
-		DBSSLMode:    os.Getenv("DB_SSLMODE"),
-		JWTSecretKey: secret,
-		TokenExpiry:  expiry,
+	if _, _, err := net.SplitHostPort(c.ServerAddr); err != nil {
+		add("SERVER_ADDR: %v", err)
+	}
+	for key, d := range map[string]time.Duration{
+		"SERVER_READ_TIMEOUT": c.ReadTimeout, "SERVER_READ_HEADER_TIMEOUT": c.ReadHeaderTimeout,
+		"SERVER_WRITE_TIMEOUT": c.WriteTimeout, "SERVER_IDLE_TIMEOUT": c.IdleTimeout,
+		"SERVER_SHUTDOWN_TIMEOUT": c.ShutdownTimeout, "SERVER_DRAIN_DELAY": c.ShutdownDrainDelay,
+		"CORS_MAX_AGE": c.CORSMaxAge,
+	} {
+		if d < 0 {
+			add("%s: must not be negative", key)
+		}
+	}
+	if c.MaxHeaderBytes <= 0 {
+		add("SERVER_MAX_HEADER_BYTES: must be positive")
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		add("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	for key, path := range map[string]string{"TLS_CERT_FILE": c.TLSCertFile, "TLS_KEY_FILE": c.TLSKeyFile} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			add("%s: %v", key, err)
+		}
+	}
+
+	oneOf("TRACING_EXPORTER", c.TracingExporter, "none", "stdout", "otlp")
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		add("TRACING_SAMPLE_RATIO: must be between 0 and 1")
+	}
+
+	oneOf("SEARCH_BACKEND", c.SearchBackend, "postgres", "memory")
+
+	oneOf("BLOB_BACKEND", c.BlobBackend, "local", "s3")
+	if strings.EqualFold(c.BlobBackend, "s3") {
+		for key, value := range map[string]string{"S3_ENDPOINT": c.S3Endpoint, "S3_BUCKET": c.S3Bucket, "S3_ACCESS_KEY": c.S3AccessKey, "S3_SECRET_KEY": c.S3SecretKey} {
+			if value == "" {
+				add("%s: required with BLOB_BACKEND=s3", key)
+			}
+		}
+	}
+	if c.PhotoMaxBytes <= 0 || c.PhotoMaxPixels <= 0 || c.PhotoMaxPerPet <= 0 {
+		add("PHOTO_MAX_BYTES, PHOTO_MAX_PIXELS and PHOTO_MAX_PER_PET: must be positive")
+	}
+	if c.SoftDeleteRetention < 0 || c.PurgeInterval < 0 {
+		add("SOFT_DELETE_RETENTION and PURGE_INTERVAL: must not be negative")
+	}
+
+	if c.RateLimitEnabled {
+		if _, err := ratelimit.ParsePolicies(c.RateLimitPolicies); err != nil {
+			add("RATE_LIMIT_POLICIES: %v", err)
+		}
+		if _, err := ratelimit.ParseAPIKeys(c.RateLimitAPIKeys); err != nil {
+			add("RATE_LIMIT_API_KEYS: %v", err)
+		}
+	}
+
+	for _, origin := range c.CORSAllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(strings.Replace(origin, "*.", "wildcard.", 1))
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || strings.Count(origin, "*") > 1 {
+			add("CORS_ALLOWED_ORIGINS: %q is not an origin such as https://app.example.com or https://*.example.com", origin)
+		}
+	}
+	if len(c.CORSAllowedMethods) == 0 {
+		add("CORS_ALLOWED_METHODS: must not be empty")
 	}
+	sort.Strings(problems)
+	return problems
 }
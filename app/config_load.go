@@ -0,0 +1,307 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigError lists every problem found while loading the configuration.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// LoadConfig builds the configuration from, in increasing precedence: built-in defaults,
+// the config file (--config or CONFIG_FILE; .yaml, .yml or .toml), environment variables
+// (including .env) and the flags in args. Parsing and validation problems are collected
+// into a single *ConfigError. flag.ErrHelp is returned after printing usage for -h.
+func LoadConfig(name string, args []string, output io.Writer) (*Config, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	flags := RegisterConfigFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return flags.Load()
+}
+
+// ConfigFlags are the configuration flags registered on a command's FlagSet, so commands
+// can define flags of their own next to them.
+type ConfigFlags struct {
+	path   *string
+	values map[string]string
+}
+
+func RegisterConfigFlags(fs *flag.FlagSet) *ConfigFlags {
+	_ = godotenv.Load() // before reading CONFIG_FILE
+	cf := &ConfigFlags{
+		path:   fs.String("config", os.Getenv("CONFIG_FILE"), "config file (.yaml, .yml or .toml)"),
+		values: map[string]string{},
+	}
+	for _, f := range configFields() {
+		key := f.key
+		fs.Func(f.flagName(), f.usage(), func(v string) error {
+			cf.values[key] = v
+			return nil
+		})
+	}
+	return cf
+}
+
+// Load merges the layers once the FlagSet has been parsed; see LoadConfig.
+func (cf *ConfigFlags) Load() (*Config, error) {
+	fields := configFields()
+
+	var problems []string
+	raw := map[string]string{}
+	for _, f := range fields {
+		raw[f.key] = f.def
+	}
+	if *cf.path != "" {
+		fromFile, err := readConfigFile(*cf.path, fields)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		for k, v := range fromFile {
+			raw[k] = v
+		}
+	}
+	for _, f := range fields {
+		// empty variables count as unset, as they always have
+		value, path := os.Getenv(f.key), os.Getenv(f.key+"_FILE")
+		set, fromFile := value != "", path != ""
+		switch {
+		case set && fromFile:
+			problems = append(problems, fmt.Sprintf("%s and %s_FILE are both set", f.key, f.key))
+		case fromFile:
+			b, err := os.ReadFile(path)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s_FILE: %v", f.key, err))
+				continue
+			}
+			raw[f.key] = strings.TrimRight(string(b), "\r\n")
+		case set:
+			raw[f.key] = value
+		}
+	}
+	for k, v := range cf.values {
+		raw[k] = v
+	}
+
+	cfg := &Config{}
+	v := reflect.ValueOf(cfg).Elem()
+	unparsed := map[string]bool{}
+	for _, f := range fields {
+		if err := setField(v.Field(f.index), raw[f.key]); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", f.key, err))
+			unparsed[f.key] = true
+		}
+	}
+	cfg.PublicURL = strings.TrimSuffix(cfg.PublicURL, "/")
+	// skip follow-on complaints about values that already failed to parse
+	for _, p := range cfg.Validate() {
+		if key, _, _ := strings.Cut(p, ":"); !unparsed[key] {
+			problems = append(problems, p)
+		}
+	}
+	if len(problems) > 0 {
+		return cfg, &ConfigError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// Redacted returns the configuration as ordered key/value pairs with secrets masked.
+func (c *Config) Redacted() *yaml.Node {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	v := reflect.ValueOf(c).Elem()
+	for _, f := range configFields() {
+		value := formatField(v.Field(f.index))
+		if f.secret && value != "" {
+			value = "[REDACTED]"
+		}
+		doc.Content = append(doc.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: strings.ToLower(f.key)},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: value},
+		)
+	}
+	return doc
+}
+
+type configField struct {
+	index  int
+	key    string
+	def    string
+	help   string
+	secret bool
+}
+
+func (f configField) flagName() string {
+	return strings.ReplaceAll(strings.ToLower(f.key), "_", "-")
+}
+
+func (f configField) usage() string {
+	usage := f.help
+	if usage == "" {
+		usage = f.key
+	} else {
+		usage += " (" + f.key + ")"
+	}
+	if f.secret {
+		usage += "; prefer " + f.key + "_FILE"
+	}
+	if f.def != "" {
+		usage += " [default " + f.def + "]"
+	}
+	return usage
+}
+
+func configFields() []configField {
+	t := reflect.TypeOf(Config{})
+	var fields []configField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := sf.Tag.Get("config")
+		if key == "" {
+			continue
+		}
+		fields = append(fields, configField{
+			index:  i,
+			key:    key,
+			def:    sf.Tag.Get("default"),
+			help:   sf.Tag.Get("help"),
+			secret: sf.Tag.Get("secret") == "true",
+		})
+	}
+	return fields
+}
+
+// readConfigFile reads a flat map of lower-case keys ("db_host: ...") into raw string values.
+func readConfigFile(path string, fields []configField) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+	doc := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("config file: %s: unsupported extension, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file: %s: %w", path, err)
+	}
+
+	known := map[string]bool{}
+	for _, f := range fields {
+		known[f.key] = true
+	}
+	out := map[string]string{}
+	var unknown []string
+	for k, v := range doc {
+		key := strings.ToUpper(strings.ReplaceAll(k, "-", "_"))
+		if !known[key] {
+			unknown = append(unknown, k)
+			continue
+		}
+		switch v := v.(type) {
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			out[key] = strings.Join(items, ",")
+		case nil:
+			out[key] = ""
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+	if len(unknown) > 0 {
+		return out, fmt.Errorf("config file: %s: unknown keys %s", path, strings.Join(unknown, ", "))
+	}
+	return out, nil
+}
+
+func setField(v reflect.Value, s string) error {
+	switch v.Interface().(type) {
+	case string:
+		v.SetString(s)
+	case time.Duration:
+		if s == "" {
+			return nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration such as 30s or 5m", s)
+		}
+		v.SetInt(int64(d))
+	case int:
+		if s == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", s)
+		}
+		v.SetInt(int64(n))
+	case bool:
+		if s == "" {
+			return nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", s)
+		}
+		v.SetBool(b)
+	case float64:
+		if s == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+		v.SetFloat(f)
+	case []string:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return errors.New("unsupported field type " + v.Type().String())
+	}
+	return nil
+}
+
+func formatField(v reflect.Value) string {
+	switch x := v.Interface().(type) {
+	case []string:
+		return strings.Join(x, ", ")
+	default:
+		return fmt.Sprint(x)
+	}
+}
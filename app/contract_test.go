@@ -0,0 +1,177 @@
+package app_test
+
+import (
+	"Go-PetStoreApp/apispec"
+	"Go-PetStoreApp/app/apptest"
+	"Go-PetStoreApp/model/web"
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// contractCase is one request of TestContract; its key is the operation it exercises,
+// e.g. "GET /pets/{petId}".
+type contractCase struct {
+	op          string
+	path        string
+	token       string
+	contentType string
+	body        string
+	status      int
+}
+
+// TestContract sends every operation of apispec.yaml through the router and the services,
+// on in-memory repositories, behind the spec validator and fails on any request or response
+// that does not match the spec.
+func TestContract(t *testing.T) {
+	spec, err := os.ReadFile("../apispec.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := apispec.Parse(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := apispec.NewValidator(doc)
+	services := apptest.NewServices(spec, "contract secret")
+	server := httptest.NewServer(v.Middleware(services.Router(nil), false))
+	defer server.Close()
+
+	ctx := context.Background()
+	aliceAuth := services.AddUser("alice", "alice@example.com", "secret1", "user")
+	adminAuth := services.AddUser("admin", "admin@example.com", "secret1", "admin")
+	carolAuth := services.AddUser("carol", "carol@example.com", "secret1", "user")
+	alice, carol := aliceAuth.User, carolAuth.User
+	aliceToken, adminToken, carolToken := aliceAuth.Token, adminAuth.Token, carolAuth.Token
+
+	cat, err := services.Taxonomy.CreateSpecies(ctx, web.SpeciesRequest{Name: "cat"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := services.Taxonomy.CreateBreed(ctx, cat.Id, web.BreedRequest{Name: "Maine Coon"}); err != nil {
+		t.Fatal(err)
+	}
+	fluffy, err := services.Pets.Create(ctx, web.PetCreateRequest{Name: "Fluffy", Species: "cat", Breed: "Maine Coon", Price: 150}, alice.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rex, err := services.Pets.Create(ctx, web.PetCreateRequest{Name: "Rex", Species: "cat", Price: 20}, alice.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	photo, photoType := photoUpload(t)
+	pet, carolID, restID := "/pets/"+strconv.Itoa(fluffy.Id), strconv.Itoa(carol.Id), strconv.Itoa(rex.Id)
+
+	cases := []contractCase{
+		{op: "POST /users/register", path: "/users/register", body: `{"username":"bob","password":"secret1","email":"bob@example.com"}`, status: 201},
+		{op: "POST /users/login", path: "/users/login", body: `{"username":"alice","password":"secret1"}`, status: 200},
+		{op: "POST /auth/refresh", path: "/auth/refresh", token: aliceToken, status: 200},
+		{op: "GET /users", path: "/users", token: aliceToken, status: 200},
+		{op: "GET /users/{id}", path: "/users/" + strconv.Itoa(alice.Id), token: aliceToken, status: 200},
+		{op: "PUT /users/{id}", path: "/users/" + strconv.Itoa(alice.Id), token: aliceToken, body: `{"username":"alice","email":"alice@example.org"}`, status: 200},
+		{op: "PATCH /users/{id}", path: "/users/" + strconv.Itoa(alice.Id), token: aliceToken, contentType: "application/merge-patch+json", body: `{"email":"alice@example.com"}`, status: 200},
+		{op: "PATCH /users/{id}/password", path: "/users/" + strconv.Itoa(alice.Id) + "/password", token: aliceToken, body: `{"old_password":"secret1","new_password":"secret2"}`, status: 200},
+		{op: "DELETE /users/{id}", path: "/users/" + carolID, token: carolToken, status: 200},
+		{op: "GET /admin/users", path: "/admin/users", token: adminToken, status: 200},
+		{op: "GET /admin/deleted/users", path: "/admin/deleted/users", token: adminToken, status: 200},
+		{op: "POST /admin/deleted/users/{id}/restore", path: "/admin/deleted/users/" + carolID + "/restore", token: adminToken, status: 200},
+
+		{op: "POST /pets", path: "/pets", token: aliceToken, body: `{"name":"Tom","species":"Cat","price":99.5,"sex":"male","weight":4.2,"microchip":"985112345678901"}`, status: 201},
+		{op: "GET /pets", path: "/pets?limit=2", token: aliceToken, status: 200},
+		{op: "GET /pets/search", path: "/pets/search?q=fluff", token: aliceToken, status: 200},
+		{op: "GET /pets/{petId}", path: pet, token: aliceToken, status: 200},
+		{op: "PUT /pets/{petId}", path: pet, token: aliceToken, body: `{"name":"Fluffy","species":"cat","breed":"Maine Coon","price":175,"color":"orange tabby"}`, status: 200},
+		{op: "PATCH /pets/{petId}", path: pet, token: aliceToken, contentType: "application/json-patch+json", body: `[{"op":"test","path":"/price","value":175},{"op":"replace","path":"/price","value":180}]`, status: 200},
+		{op: "GET /pets/{petId}/history", path: pet + "/history", token: aliceToken, status: 200},
+		{op: "POST /pets/{petId}/photos", path: pet + "/photos", token: aliceToken, contentType: photoType, body: photo, status: 201},
+		{op: "GET /pets/{petId}/photos", path: pet + "/photos", token: aliceToken, status: 200},
+		{op: "PUT /pets/{petId}/photos", path: pet + "/photos", token: aliceToken, body: `{"photo_ids":[1]}`, status: 200},
+		{op: "PUT /pets/{petId}/photos/{photoId}/primary", path: pet + "/photos/1/primary", token: aliceToken, status: 200},
+		{op: "DELETE /pets/{petId}/photos/{photoId}", path: pet + "/photos/1", token: aliceToken, status: 204},
+		{op: "DELETE /pets/{petId}", path: "/pets/" + restID, token: aliceToken, status: 204},
+		{op: "GET /admin/pets", path: "/admin/pets?owner_id=" + strconv.Itoa(alice.Id), token: adminToken, status: 200},
+		{op: "GET /admin/deleted/pets", path: "/admin/deleted/pets", token: adminToken, status: 200},
+		{op: "POST /admin/deleted/pets/{petId}/restore", path: "/admin/deleted/pets/" + restID + "/restore", token: adminToken, status: 200},
+		{op: "GET /admin/audit", path: "/admin/audit?entity=pet&limit=10", token: adminToken, status: 200},
+
+		{op: "GET /species", path: "/species", token: aliceToken, status: 200},
+		{op: "GET /species/{speciesId}/breeds", path: "/species/" + strconv.Itoa(cat.Id) + "/breeds", token: aliceToken, status: 200},
+		{op: "POST /admin/species", path: "/admin/species", token: adminToken, body: `{"name":"Dog"}`, status: 201},
+		{op: "PUT /admin/species/{speciesId}", path: "/admin/species/2", token: adminToken, body: `{"name":"dogs"}`, status: 200},
+		{op: "POST /admin/species/{speciesId}/breeds", path: "/admin/species/2/breeds", token: adminToken, body: `{"name":"Beagle"}`, status: 201},
+		{op: "PUT /admin/breeds/{breedId}", path: "/admin/breeds/2", token: adminToken, body: `{"name":"Basset"}`, status: 200},
+		{op: "DELETE /admin/breeds/{breedId}", path: "/admin/breeds/2", token: adminToken, status: 204},
+		{op: "DELETE /admin/species/{speciesId}", path: "/admin/species/2", token: adminToken, status: 204},
+
+		{op: "GET /admin/log-level", path: "/admin/log-level", token: adminToken, status: 200},
+		{op: "PUT /admin/log-level", path: "/admin/log-level", token: adminToken, body: `{"level":"info"}`, status: 200},
+	}
+
+	covered := map[string]bool{}
+	for _, c := range cases {
+		covered[c.op] = true
+		method, _, _ := strings.Cut(c.op, " ")
+		req, err := http.NewRequest(method, server.URL+"/api"+c.path, strings.NewReader(c.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.contentType != "" {
+			req.Header.Set("Content-Type", c.contentType)
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != c.status {
+			t.Errorf("%s %s: status %d, want %d: %s", method, c.path, resp.StatusCode, c.status, body)
+		}
+	}
+
+	for template, item := range doc.Paths {
+		for _, method := range []string{"GET", "PUT", "POST", "DELETE", "PATCH"} {
+			if item.Operation(method) != nil && !covered[method+" "+template] {
+				t.Errorf("no contract case for %s %s", method, template)
+			}
+		}
+	}
+	if violations := v.Violations(); len(violations) != 0 {
+		for _, violation := range violations {
+			t.Error(violation)
+		}
+	}
+}
+
+// photoUpload returns a multipart body with a small PNG as the photo, and its content type.
+func photoUpload(t *testing.T) (string, string) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("photo", "photo.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(part, image.NewRGBA(image.Rect(0, 0, 4, 3))); err != nil {
+		t.Fatal(err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body.String(), form.FormDataContentType()
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"Go-PetStoreApp/service"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// RunPurge hard-deletes the records soft-deleted more than cfg.SoftDeleteRetention ago, at
+// start and then every cfg.PurgeInterval, until ctx is cancelled. A zero interval disables it.
+func RunPurge(ctx context.Context, cfg *Config, retention service.RetentionService) {
+	if cfg.PurgeInterval <= 0 {
+		slog.Info("purge of deleted records disabled")
+		return
+	}
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		if err := purgeOnce(ctx, retention, time.Now().Add(-cfg.SoftDeleteRetention)); err != nil && ctx.Err() == nil {
+			slog.Error("purging deleted records", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeOnce turns the panics the repositories use for database errors into an error, so a
+// failed run does not take the server down.
+func purgeOnce(ctx context.Context, retention service.RetentionService, deletedBefore time.Time) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	_, err = retention.Purge(ctx, deletedBefore)
+	return err
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"Go-PetStoreApp/controller"
+	"Go-PetStoreApp/exception"
+	"Go-PetStoreApp/metrics"
+	"Go-PetStoreApp/middleware"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func NewRouter(userController controller.UserController, petController controller.PetController, taxonomyController controller.TaxonomyController, photoController controller.PetPhotoController, auditController controller.AuditController, media http.Handler, docsController controller.DocsController, healthController controller.HealthController, logController controller.LogController, jwtMiddleware *middleware.JWTMiddleware, rateLimiter *middleware.RateLimiter) *httprouter.Router {
+	router := httprouter.New()
+
+	// wrap tags requests with the route template for metrics and logs and applies the rate
+	// limit policy for the route, if any; route registers the result
+	wrap := func(method, path string, handle httprouter.Handle) httprouter.Handle {
+		return middleware.WithRoute(path, rateLimiter.Limit(method, path, handle))
+	}
+	route := func(method, path string, handle httprouter.Handle) {
+		router.Handle(method, path, wrap(method, path, handle))
+	}
+
+	// --- Probes ---
+	route(http.MethodGet, "/healthz", healthController.Liveness)
+	route(http.MethodGet, "/readyz", healthController.Readiness)
+
+	// --- User endpoints ---
+	route(http.MethodPost, "/api/users/register", userController.Register)
+	route(http.MethodPost, "/api/users/login", userController.Login)
+	route(http.MethodPost, "/api/auth/refresh", userController.RefreshToken)
+
+	route(http.MethodGet, "/api/users/:id", jwtMiddleware.Authenticate(userController.FindById))
+	route(http.MethodPut, "/api/users/:id", jwtMiddleware.Authenticate(userController.Update))
+	route(http.MethodPatch, "/api/users/:id", jwtMiddleware.Authenticate(userController.Patch))
+	route(http.MethodPatch, "/api/users/:id/password", jwtMiddleware.Authenticate(userController.ChangePassword))
+	route(http.MethodDelete, "/api/users/:id", jwtMiddleware.Authenticate(userController.Delete))
+	route(http.MethodGet, "/api/users", jwtMiddleware.Authenticate(userController.FindAll))
+
+	// Admin-only users
+	route(http.MethodGet, "/api/admin/users", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", userController.FindAll)))
+
+	// --- Pet endpoints ---
+	route(http.MethodGet, "/api/pets", jwtMiddleware.Authenticate(petController.FindAll))
+	route(http.MethodPost, "/api/pets", jwtMiddleware.Authenticate(petController.Create))
+	// httprouter cannot register /api/pets/search next to /api/pets/:petId, so the :petId
+	// route hands "search" over to the search handler
+	findPet := wrap(http.MethodGet, "/api/pets/:petId", jwtMiddleware.Authenticate(petController.FindById))
+	searchPets := wrap(http.MethodGet, "/api/pets/search", jwtMiddleware.Authenticate(petController.Search))
+	router.GET("/api/pets/:petId", func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if ps.ByName("petId") == "search" {
+			searchPets(w, r, ps)
+			return
+		}
+		findPet(w, r, ps)
+	})
+	route(http.MethodPut, "/api/pets/:petId", jwtMiddleware.Authenticate(petController.Update))
+	route(http.MethodPatch, "/api/pets/:petId", jwtMiddleware.Authenticate(petController.Patch))
+	route(http.MethodDelete, "/api/pets/:petId", jwtMiddleware.Authenticate(petController.Delete))
+	route(http.MethodGet, "/api/pets/:petId/history", jwtMiddleware.Authenticate(auditController.PetHistory))
+
+	// Pet photos (multipart upload; PUT on the collection reorders)
+	route(http.MethodPost, "/api/pets/:petId/photos", jwtMiddleware.Authenticate(photoController.Upload))
+	route(http.MethodGet, "/api/pets/:petId/photos", jwtMiddleware.Authenticate(photoController.FindAll))
+	route(http.MethodPut, "/api/pets/:petId/photos", jwtMiddleware.Authenticate(photoController.Reorder))
+	route(http.MethodPut, "/api/pets/:petId/photos/:photoId/primary", jwtMiddleware.Authenticate(photoController.SetPrimary))
+	route(http.MethodDelete, "/api/pets/:petId/photos/:photoId", jwtMiddleware.Authenticate(photoController.Delete))
+
+	// Photo files of the local blob backend
+	if media != nil {
+		route(http.MethodGet, MediaPath+"/*filepath", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+			media.ServeHTTP(w, r)
+		})
+	}
+
+	// Admin-only pets
+	route(http.MethodGet, "/api/admin/pets", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", petController.FindAll)))
+
+	// Soft-deleted users and pets, restorable until purged (admin-only)
+	route(http.MethodGet, "/api/admin/deleted/users", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", userController.FindDeleted)))
+	route(http.MethodPost, "/api/admin/deleted/users/:id/restore", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", userController.Restore)))
+	route(http.MethodGet, "/api/admin/deleted/pets", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", petController.FindDeleted)))
+	route(http.MethodPost, "/api/admin/deleted/pets/:petId/restore", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", petController.Restore)))
+
+	// Audit log of changes to users and pets (admin-only)
+	route(http.MethodGet, "/api/admin/audit", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", auditController.FindAll)))
+
+	// --- Species and breeds catalog (changes are admin-only) ---
+	route(http.MethodGet, "/api/species", jwtMiddleware.Authenticate(taxonomyController.FindAllSpecies))
+	route(http.MethodGet, "/api/species/:speciesId/breeds", jwtMiddleware.Authenticate(taxonomyController.FindBreeds))
+	route(http.MethodPost, "/api/admin/species", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", taxonomyController.CreateSpecies)))
+	route(http.MethodPut, "/api/admin/species/:speciesId", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", taxonomyController.UpdateSpecies)))
+	route(http.MethodDelete, "/api/admin/species/:speciesId", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", taxonomyController.DeleteSpecies)))
+	route(http.MethodPost, "/api/admin/species/:speciesId/breeds", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", taxonomyController.CreateBreed)))
+	route(http.MethodPut, "/api/admin/breeds/:breedId", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", taxonomyController.UpdateBreed)))
+	route(http.MethodDelete, "/api/admin/breeds/:breedId", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", taxonomyController.DeleteBreed)))
+
+	// Admin-only runtime log level
+	route(http.MethodGet, "/api/admin/log-level", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", logController.GetLevel)))
+	route(http.MethodPut, "/api/admin/log-level", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", logController.SetLevel)))
+
+	// --- Metrics ---
+	metricsHandler := metrics.Handler()
+	route(http.MethodGet, "/metrics", func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		metricsHandler.ServeHTTP(w, r)
+	})
+
+	// --- API docs ---
+	route(http.MethodGet, "/openapi.yaml", docsController.OpenAPIYAML)
+	route(http.MethodGet, "/openapi.json", docsController.OpenAPIJSON)
+	route(http.MethodGet, "/docs", docsController.SwaggerUI)
+	route(http.MethodGet, "/docs/*filepath", docsController.SwaggerUI)
+
+	// Panic handler for JSON error response
+	router.PanicHandler = exception.ErrorHandler
+
+	return router
+}
@@ -0,0 +1,141 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+func NewServer(cfg *Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.ServerAddr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// Serve runs server until ctx is cancelled. It then calls beforeShutdown (if set), keeps
+// serving for cfg.ShutdownDrainDelay so load balancers notice the failing readiness probe,
+// stops accepting connections and waits up to cfg.ShutdownTimeout for in-flight requests.
+// It serves TLS when cert and key files are set.
+func Serve(ctx context.Context, cfg *Config, server *http.Server, beforeShutdown func()) error {
+	errCh := make(chan error, 1)
+	go func() {
+		if cfg.TLSCertFile != "" && cfg.TLSKeyFile != "" {
+			reloader, err := newCertReloader(cfg.TLSCertFile, cfg.TLSKeyFile)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			server.TLSConfig = &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				GetCertificate: reloader.GetCertificate,
+			}
+			slog.Info("listening", "addr", server.Addr, "tls", true)
+			errCh <- server.ListenAndServeTLS("", "")
+			return
+		}
+		slog.Info("listening", "addr", server.Addr, "tls", false)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	if beforeShutdown != nil {
+		beforeShutdown()
+	}
+	if cfg.ShutdownDrainDelay > 0 {
+		slog.Info("shutdown requested, draining", "delay", cfg.ShutdownDrainDelay)
+		select {
+		case <-time.After(cfg.ShutdownDrainDelay):
+		case err := <-errCh:
+			return err
+		}
+	}
+
+	slog.Info("shutting down, waiting for in-flight requests", "timeout", cfg.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// certReloader serves a key pair and reloads it when either file's modification time changes,
+// so rotated certificates are picked up without a restart.
+type certReloader struct {
+	certFile, keyFile string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	certMod   time.Time
+	keyMod    time.Time
+	lastCheck time.Time
+}
+
+// certCheckInterval limits how often the files are stat'ed during handshakes.
+const certCheckInterval = 10 * time.Second
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) reload() error {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return err
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert = &cert
+	r.certMod = certInfo.ModTime()
+	r.keyMod = keyInfo.ModTime()
+	return nil
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) >= certCheckInterval {
+		r.lastCheck = time.Now()
+		certInfo, certErr := os.Stat(r.certFile)
+		keyInfo, keyErr := os.Stat(r.keyFile)
+		if certErr == nil && keyErr == nil && (!certInfo.ModTime().Equal(r.certMod) || !keyInfo.ModTime().Equal(r.keyMod)) {
+			// keep serving the old pair if the new one is half-written or invalid
+			if err := r.reload(); err != nil {
+				slog.Error("tls reload failed, keeping previous certificate", "error", err)
+			} else {
+				slog.Info("tls certificate reloaded", "file", r.certFile)
+			}
+		}
+	}
+	return r.cert, nil
+}
@@ -0,0 +1,123 @@
+// Package audit describes changes for the audit log: who made them, from where, and what
+// they changed. The HTTP middleware puts a Source in the request context; services read it
+// when they write an entry in the transaction that makes the change.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+)
+
+// Actions and entities of audit entries.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionPurge   = "purge" // a hard delete of a deleted entity by the purge job
+
+	EntityPet  = "pet"
+	EntityUser = "user"
+)
+
+// Source is where a change comes from. ActorID is 0 for changes made outside a request by an
+// authenticated user, such as admin commands.
+type Source struct {
+	ActorID int
+	IP      string
+}
+
+type sourceKey struct{}
+
+// WithSource stores an empty Source in ctx for the middleware to fill in, unless there is one.
+func WithSource(ctx context.Context, ip string) context.Context {
+	if _, ok := ctx.Value(sourceKey{}).(*Source); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, sourceKey{}, &Source{IP: ip})
+}
+
+// SetActor records the authenticated user of the request, if ctx has a Source.
+func SetActor(ctx context.Context, userID int) {
+	if s, ok := ctx.Value(sourceKey{}).(*Source); ok {
+		s.ActorID = userID
+	}
+}
+
+// FromContext returns the Source of the request, or the zero Source.
+func FromContext(ctx context.Context) Source {
+	if s, ok := ctx.Value(sourceKey{}).(*Source); ok {
+		return *s
+	}
+	return Source{}
+}
+
+// ignored are fields that change as a side effect or are derived, not edited.
+var ignored = map[string]bool{"updated_at": true, "version": true, "age": true, "photos": true}
+
+// Diff compares two JSON-encodable snapshots of an entity and returns the fields that differ,
+// as JSON objects with their old and new values. A nil before (a create) or after returns
+// the other snapshot in full and null for itself.
+func Diff(before, after any) (json.RawMessage, json.RawMessage, error) {
+	old, err := fields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	cur, err := fields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if old != nil && cur != nil {
+		for name, value := range old {
+			if other, ok := cur[name]; ok && reflect.DeepEqual(value, other) {
+				delete(old, name)
+				delete(cur, name)
+			}
+		}
+		// omitted fields were cleared or set; show them as null on the other side
+		for name := range old {
+			if _, ok := cur[name]; !ok {
+				cur[name] = nil
+			}
+		}
+		for name := range cur {
+			if _, ok := old[name]; !ok {
+				old[name] = nil
+			}
+		}
+	}
+	oldJSON, err := encode(old)
+	if err != nil {
+		return nil, nil, err
+	}
+	curJSON, err := encode(cur)
+	return oldJSON, curJSON, err
+}
+
+func fields(v any) (map[string]any, error) {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil() {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for name := range m {
+		if ignored[name] {
+			delete(m, name)
+		}
+	}
+	return m, nil
+}
+
+func encode(m map[string]any) (json.RawMessage, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
@@ -0,0 +1,44 @@
+// Package blob stores binary objects such as pet photos under slash-separated keys. Local
+// keeps them in a directory, for development and single-node deployments; S3 talks to any
+// S3-compatible service (AWS S3, MinIO, ...).
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+type Store interface {
+	// Put stores data under key, replacing any object already there.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get opens the object under key, or returns ErrNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object under key; a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL is where clients download the object from.
+	URL(key string) string
+}
+
+// checkKey accepts keys such as "pets/42/9f86d081/thumb.jpg": relative, without empty, "."
+// or ".." segments, and made of characters that need no escaping in a URL or file name.
+func checkKey(key string) error {
+	if key == "" {
+		return errors.New("empty blob key")
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+		for _, c := range segment {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+				return fmt.Errorf("invalid blob key %q", key)
+			}
+		}
+	}
+	return nil
+}
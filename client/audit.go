@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"Go-PetStoreApp/model/web"
+)
+
+// AuditParams are the query parameters of GET /admin/audit. Zero values are omitted.
+type AuditParams struct {
+	ActorID   int
+	Entity    string // "pet" or "user"
+	EntityID  int
+	Action    string // "create", "update", "delete" or "restore"
+	RequestID string
+	// From and To are inclusive dates (2006-01-02) or RFC 3339 timestamps.
+	From  string
+	To    string
+	Page  int
+	Limit int
+}
+
+func (p AuditParams) query() string {
+	q := url.Values{}
+	for name, id := range map[string]int{"actor_id": p.ActorID, "entity_id": p.EntityID, "page": p.Page, "limit": p.Limit} {
+		if id > 0 {
+			q.Set(name, strconv.Itoa(id))
+		}
+	}
+	for name, value := range map[string]string{"entity": p.Entity, "action": p.Action, "request_id": p.RequestID, "from": p.From, "to": p.To} {
+		if value != "" {
+			q.Set(name, value)
+		}
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
+
+// AdminListAudit returns a page of the audit log, newest first.
+func (c *Client) AdminListAudit(ctx context.Context, params AuditParams) (web.AuditPageResponse, error) {
+	var resp web.AuditPageResponse
+	err := c.call(ctx, http.MethodGet, "/admin/audit"+params.query(), nil, &resp, true)
+	return resp, err
+}
+
+// PetHistory returns a page of the changes to one of the caller's pets, newest first.
+func (c *Client) PetHistory(ctx context.Context, petID, page, limit int) (web.AuditPageResponse, error) {
+	q := url.Values{}
+	if page > 0 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	path := "/pets/" + strconv.Itoa(petID) + "/history"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	var resp web.AuditPageResponse
+	err := c.call(ctx, http.MethodGet, path, nil, &resp, true)
+	return resp, err
+}
@@ -0,0 +1,216 @@
+// Package client is a typed Go client for the Pet Store API.
+//
+//	c := client.New("http://localhost:3000/api")
+//	if _, err := c.Login(ctx, web.UserLoginRequest{Username: "u", Password: "p"}); err != nil { ... }
+//	for pet, err := range c.AllPets(ctx, client.ListPetsParams{Species: []string{"dog"}}) { ... }
+//
+// The client stores the bearer token returned by Register/Login and refreshes it through
+// /api/auth/refresh shortly before it expires, or once after a 401 on an authenticated call.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"Go-PetStoreApp/model/web"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// DefaultRefreshWindow is how long before expiry a token is proactively refreshed.
+const DefaultRefreshWindow = 5 * time.Minute
+
+type Client struct {
+	baseURL       string
+	httpClient    *http.Client
+	refreshWindow time.Duration
+	now           func() time.Time
+
+	mu    sync.Mutex
+	token string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient, e.g. to set timeouts or a custom transport.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithToken starts the client with an existing bearer token.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithRefreshWindow changes DefaultRefreshWindow; zero disables proactive refresh.
+func WithRefreshWindow(d time.Duration) Option {
+	return func(c *Client) { c.refreshWindow = d }
+}
+
+// New creates a client for baseURL, which includes the "/api" prefix.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		httpClient:    http.DefaultClient,
+		refreshWindow: DefaultRefreshWindow,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Token returns the current bearer token.
+func (c *Client) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// SetToken replaces the bearer token.
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	c.token = token
+	c.mu.Unlock()
+}
+
+// call issues a request and decodes the "data" field of the response envelope into out.
+// Authenticated calls refresh the token when it is about to expire and retry once on 401.
+func (c *Client) call(ctx context.Context, method, path string, in, out interface{}, authenticated bool) error {
+	if authenticated {
+		if err := c.refreshIfExpiring(ctx); err != nil {
+			return err
+		}
+	}
+	err := c.do(ctx, method, path, in, out, authenticated)
+	if authenticated && IsUnauthorized(err) && c.Token() != "" {
+		if refreshErr := c.refresh(ctx); refreshErr == nil {
+			err = c.do(ctx, method, path, in, out, authenticated)
+		}
+	}
+	return err
+}
+
+// rawBody is a request body sent as is rather than encoded as JSON, e.g. a multipart upload.
+type rawBody struct {
+	contentType string
+	data        []byte
+}
+
+// conditional sends body, which may be nil, with an If-Match header unless ifMatch is empty.
+type conditional struct {
+	body    interface{}
+	ifMatch string
+}
+
+// ETag is the entity tag of a user or pet at version, for the If-Match of a later change.
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}, authenticated bool) error {
+	var ifMatch string
+	if v, ok := in.(conditional); ok {
+		in, ifMatch = v.body, v.ifMatch
+	}
+	var body io.Reader
+	contentType := "application/json"
+	switch v := in.(type) {
+	case nil:
+	case rawBody:
+		body, contentType = bytes.NewReader(v.data), v.contentType
+	default:
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	if token := c.Token(); authenticated && token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	// continue the caller's trace, if the application configured an OpenTelemetry propagator
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		apiErr := newAPIError(resp.StatusCode, resp.Header.Get("X-Request-ID"), raw)
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(secs) * time.Second
+		}
+		return apiErr
+	}
+	if out == nil || len(bytes.TrimSpace(raw)) == 0 {
+		return nil
+	}
+
+	envelope := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return fmt.Errorf("client: decoding response: %w", err)
+	}
+	if len(envelope.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("client: decoding response data: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) refreshIfExpiring(ctx context.Context) error {
+	token := c.Token()
+	if token == "" || c.refreshWindow <= 0 {
+		return nil
+	}
+	claims := jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, &claims); err != nil || claims.ExpiresAt == nil {
+		return nil
+	}
+	if c.now().Add(c.refreshWindow).Before(claims.ExpiresAt.Time) {
+		return nil
+	}
+	return c.refresh(ctx)
+}
+
+func (c *Client) refresh(ctx context.Context) error {
+	var resp web.AuthResponse
+	if err := c.do(ctx, http.MethodPost, "/auth/refresh", nil, &resp, true); err != nil {
+		return err
+	}
+	c.SetToken(resp.Token)
+	return nil
+}
@@ -0,0 +1,212 @@
+package client_test
+
+import (
+	"Go-PetStoreApp/app/apptest"
+	"Go-PetStoreApp/client"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/middleware"
+	"Go-PetStoreApp/model/web"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const jwtSecret = "client test secret"
+
+// testServer runs the application's router over the services of apptest, with the species
+// cat and dog, and counts the requests per path.
+type testServer struct {
+	*httptest.Server
+	services *apptest.Services
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func newTestServer(t *testing.T, revocations middleware.TokenRevocations) *testServer {
+	spec, err := os.ReadFile("../apispec.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{services: apptest.NewServices(spec, jwtSecret), requests: map[string]int{}}
+	for _, species := range []string{"cat", "dog"} {
+		if _, err := s.services.Taxonomy.CreateSpecies(context.Background(), web.SpeciesRequest{Name: species}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	router := s.services.Router(revocations)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+		s.mu.Unlock()
+		router.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// login adds a user and returns a client with their token.
+func (s *testServer) login(t *testing.T, username string) (*client.Client, web.UserResponse) {
+	u := s.services.AddUser(username, username+"@example.com", "secret1", "user").User
+	c := client.New(s.URL+"/api", client.WithHTTPClient(s.Client()))
+	if _, err := c.Login(context.Background(), web.UserLoginRequest{Username: username, Password: "secret1"}); err != nil {
+		t.Fatal(err)
+	}
+	return c, u
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	s := newTestServer(t, nil)
+	ctx := context.Background()
+
+	c := client.New(s.URL+"/api", client.WithHTTPClient(s.Client()))
+	registered, err := c.Register(ctx, web.UserRegisterRequest{Username: "bob", Password: "secret1", Email: "bob@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registered.User.Username != "bob" || registered.Token == "" || c.Token() != registered.Token {
+		t.Fatalf("register: got %+v, client token %q", registered, c.Token())
+	}
+
+	c = client.New(s.URL+"/api", client.WithHTTPClient(s.Client()))
+	if _, err := c.Login(ctx, web.UserLoginRequest{Username: "bob", Password: "wrong"}); !errors.Is(err, client.ErrUnauthorized) {
+		t.Fatalf("login with a wrong password: got %v, want ErrUnauthorized", err)
+	}
+	loggedIn, err := c.Login(ctx, web.UserLoginRequest{Username: "bob", Password: "secret1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Token() != loggedIn.Token {
+		t.Fatalf("login: client token %q, want %q", c.Token(), loggedIn.Token)
+	}
+	u, err := c.GetUser(ctx, registered.User.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Email != "bob@example.com" {
+		t.Fatalf("get user: got %+v", u)
+	}
+}
+
+// issuedBefore revokes every token issued before it, on the API routes only; the refresh
+// endpoint checks the user service's revocations, which know nothing of it.
+type issuedBefore time.Time
+
+func (b issuedBefore) TokenRevoked(ctx context.Context, userID int, issuedAt time.Time) (bool, error) {
+	return issuedAt.Before(time.Time(b)), nil
+}
+
+func TestRefreshAndRetryOn401(t *testing.T) {
+	s := newTestServer(t, issuedBefore(time.Now().Add(-time.Minute)))
+	u := s.services.AddUser("alice", "alice@example.com", "secret1", "user").User
+	issued := time.Now().Add(-time.Hour)
+	old, err := jwt.NewWithClaims(jwt.SigningMethodHS256, helper.JWTClaims{
+		UserID: u.Id, Username: u.Username, Email: u.Email, Role: u.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(issued),
+			NotBefore: jwt.NewNumericDate(issued),
+		},
+	}).SignedString([]byte(jwtSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := client.New(s.URL+"/api", client.WithHTTPClient(s.Client()), client.WithToken(old), client.WithRefreshWindow(0))
+	got, err := c.GetUser(context.Background(), u.Id)
+	if err != nil {
+		t.Fatalf("get user after the 401: %v", err)
+	}
+	if got.Id != u.Id {
+		t.Fatalf("get user: got %+v", got)
+	}
+	if c.Token() == old {
+		t.Fatal("the client kept the rejected token")
+	}
+	path := "/api/users/" + strconv.Itoa(u.Id)
+	if n, refreshes := s.count(path), s.count("/api/auth/refresh"); n != 2 || refreshes != 1 {
+		t.Fatalf("got %d requests and %d refreshes, want the request, one refresh and one retry", n, refreshes)
+	}
+}
+
+func TestErrorTypes(t *testing.T) {
+	s := newTestServer(t, nil)
+	ctx := context.Background()
+	c, _ := s.login(t, "alice")
+
+	if _, err := c.GetPet(ctx, 999); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("missing pet: got %v, want ErrNotFound", err)
+	}
+
+	req := web.PetCreateRequest{Name: "Fluffy", Species: "cat", Price: 150, PetProfile: web.PetProfile{Microchip: "985112345678901"}}
+	pet, err := c.CreatePet(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.CreatePet(ctx, req)
+	var apiErr *client.APIError
+	if !errors.Is(err, client.ErrConflict) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("duplicate microchip: got %v, want a 409 ErrConflict", err)
+	}
+
+	update := web.PetUpdateRequest{Name: "Fluffy", Species: "cat", Price: 175, IfMatch: client.ETag(pet.Version)}
+	if _, err := c.UpdatePet(ctx, pet.Id, update); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UpdatePet(ctx, pet.Id, update); !errors.Is(err, client.ErrPreconditionFailed) {
+		t.Errorf("stale ETag: got %v, want ErrPreconditionFailed", err)
+	}
+}
+
+func TestAllPetsReachesLastPage(t *testing.T) {
+	s := newTestServer(t, nil)
+	ctx := context.Background()
+	c, u := s.login(t, "alice")
+	other, _ := s.login(t, "bob")
+
+	var want []int
+	for i := 0; i < 5; i++ {
+		pet, err := c.CreatePet(ctx, web.PetCreateRequest{Name: "Pet " + strconv.Itoa(i), Species: "cat", Price: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, pet.Id)
+		if _, err := other.CreatePet(ctx, web.PetCreateRequest{Name: "Other " + strconv.Itoa(i), Species: "dog", Price: 10}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	slices.Reverse(want) // listings are newest first
+	before := s.count("/api/pets")
+	var got []int
+	for pet, err := range c.AllPets(ctx, client.ListPetsParams{Limit: 2}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pet.OwnerId != u.Id {
+			t.Fatalf("got pet %d of user %d", pet.Id, pet.OwnerId)
+		}
+		got = append(got, pet.Id)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got pets %v, want %v", got, want)
+	}
+	if pages := s.count("/api/pets") - before; pages != 3 {
+		t.Fatalf("fetched %d pages of 2 for 5 pets, want 3", pages)
+	}
+}
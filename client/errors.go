@@ -0,0 +1,109 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	// ErrPreconditionFailed means an If-Match ETag was out of date; get the entity again.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrRateLimited        = errors.New("rate limited")
+	ErrServer             = errors.New("server error")
+)
+
+// FieldError is a single request validation failure reported by the API.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// APIError is returned for every non-2xx response. Use errors.Is with the Err* values
+// to branch on the status class.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Fields     []FieldError
+	RequestID  string        // quote this when reporting a failure
+	RetryAfter time.Duration // set on 429 responses
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.RequestID != "" {
+		msg += " (request " + e.RequestID + ")"
+	}
+	return msg
+}
+
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusConflict:
+		return ErrConflict
+	case e.StatusCode == http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrServer
+	}
+	return nil
+}
+
+// IsUnauthorized reports whether err is a 401 from the API.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// newAPIError understands the three error shapes the API emits: the envelope with
+// {"error": ..., "fields": [...]} data, the envelope with a string data, and the
+// bare {"error": ...} body written by the JWT middleware.
+func newAPIError(status int, requestID string, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: status, RequestID: requestID}
+
+	var envelope struct {
+		Data  json.RawMessage `json:"data"`
+		Error string          `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return apiErr
+	}
+	if envelope.Error != "" {
+		apiErr.Message = envelope.Error
+		return apiErr
+	}
+
+	var msg string
+	if json.Unmarshal(envelope.Data, &msg) == nil {
+		apiErr.Message = msg
+		return apiErr
+	}
+	var data struct {
+		Error  string       `json:"error"`
+		Fields []FieldError `json:"fields"`
+	}
+	if json.Unmarshal(envelope.Data, &data) == nil {
+		apiErr.Message = data.Error
+		apiErr.Fields = data.Fields
+	}
+	return apiErr
+}
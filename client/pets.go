@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"Go-PetStoreApp/model/web"
+)
+
+// ListPetsParams are the query parameters of GET /pets. Zero values are omitted.
+type ListPetsParams struct {
+	Page    int
+	Limit   int
+	Species []string
+	Name    string // case-insensitive substring
+	// MinPrice and MaxPrice filter when non-nil.
+	MinPrice *float64
+	MaxPrice *float64
+	// CreatedFrom and CreatedTo are inclusive dates (2006-01-02) or RFC 3339 timestamps.
+	CreatedFrom string
+	CreatedTo   string
+	UpdatedFrom string
+	UpdatedTo   string
+	Sex         string // "male" or "female"
+	Color       string // case-insensitive substring
+	Neutered    *bool
+	Microchip   string
+	// BornFrom and BornTo are inclusive dates; MinAge and MaxAge are whole years.
+	BornFrom  string
+	BornTo    string
+	MinAge    *int
+	MaxAge    *int
+	MinWeight *float64 // kg
+	MaxWeight *float64
+	Sort      string // e.g. "price,-created_at"
+	OwnerID   int    // admin only
+
+	// Cursor is a NextCursor or PrevCursor from an earlier page; Page is then ignored.
+	Cursor string
+	// SkipTotal leaves out the total count, which saves the server a COUNT(*).
+	SkipTotal bool
+}
+
+func (p ListPetsParams) query() string {
+	q := url.Values{}
+	if p.Page > 0 {
+		q.Set("page", strconv.Itoa(p.Page))
+	}
+	if p.Limit > 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if len(p.Species) > 0 {
+		q.Set("species", strings.Join(p.Species, ","))
+	}
+	if p.Name != "" {
+		q.Set("name", p.Name)
+	}
+	for key, value := range map[string]*float64{
+		"min_price": p.MinPrice, "max_price": p.MaxPrice,
+		"min_weight": p.MinWeight, "max_weight": p.MaxWeight,
+	} {
+		if value != nil {
+			q.Set(key, strconv.FormatFloat(*value, 'f', -1, 64))
+		}
+	}
+	for key, value := range map[string]*int{"min_age": p.MinAge, "max_age": p.MaxAge} {
+		if value != nil {
+			q.Set(key, strconv.Itoa(*value))
+		}
+	}
+	if p.Neutered != nil {
+		q.Set("neutered", strconv.FormatBool(*p.Neutered))
+	}
+	for key, value := range map[string]string{
+		"created_from": p.CreatedFrom, "created_to": p.CreatedTo,
+		"updated_from": p.UpdatedFrom, "updated_to": p.UpdatedTo,
+		"born_from": p.BornFrom, "born_to": p.BornTo,
+		"sex": p.Sex, "color": p.Color, "microchip": p.Microchip,
+		"sort": p.Sort,
+	} {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	if p.OwnerID > 0 {
+		q.Set("owner_id", strconv.Itoa(p.OwnerID))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	if p.SkipTotal {
+		q.Set("total", "false")
+	}
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
+
+func (c *Client) CreatePet(ctx context.Context, req web.PetCreateRequest) (web.PetResponse, error) {
+	var resp web.PetResponse
+	err := c.call(ctx, http.MethodPost, "/pets", req, &resp, true)
+	return resp, err
+}
+
+func (c *Client) GetPet(ctx context.Context, id int) (web.PetResponse, error) {
+	var resp web.PetResponse
+	err := c.call(ctx, http.MethodGet, "/pets/"+strconv.Itoa(id), nil, &resp, true)
+	return resp, err
+}
+
+// UpdatePet sends req.IfMatch, e.g. ETag(pet.Version), as If-Match; the update then fails
+// with ErrPreconditionFailed if the pet was changed since.
+func (c *Client) UpdatePet(ctx context.Context, id int, req web.PetUpdateRequest) (web.PetResponse, error) {
+	var resp web.PetResponse
+	err := c.call(ctx, http.MethodPut, "/pets/"+strconv.Itoa(id), conditional{req, req.IfMatch}, &resp, true)
+	return resp, err
+}
+
+// PatchPet changes only the fields in req.Patch, a JSON Merge Patch or a JSON Patch as
+// req.ContentType says (jsonpatch.MergePatchType or jsonpatch.JSONPatchType).
+func (c *Client) PatchPet(ctx context.Context, req web.PatchRequest) (web.PetResponse, error) {
+	var resp web.PetResponse
+	body := rawBody{contentType: req.ContentType, data: req.Patch}
+	err := c.call(ctx, http.MethodPatch, "/pets/"+strconv.Itoa(req.Id), conditional{body, req.IfMatch}, &resp, true)
+	return resp, err
+}
+
+// DeletePet deletes the pet; a non-empty ifMatch makes it conditional, as in UpdatePet.
+func (c *Client) DeletePet(ctx context.Context, id int, ifMatch string) error {
+	return c.call(ctx, http.MethodDelete, "/pets/"+strconv.Itoa(id), conditional{nil, ifMatch}, nil, true)
+}
+
+// ListPets fetches a single page.
+func (c *Client) ListPets(ctx context.Context, params ListPetsParams) (web.PetPageResponse, error) {
+	var resp web.PetPageResponse
+	err := c.call(ctx, http.MethodGet, "/pets"+params.query(), nil, &resp, true)
+	return resp, err
+}
+
+// AdminListPets fetches a single page of all pets; requires an admin token.
+func (c *Client) AdminListPets(ctx context.Context, params ListPetsParams) (web.PetPageResponse, error) {
+	var resp web.PetPageResponse
+	err := c.call(ctx, http.MethodGet, "/admin/pets"+params.query(), nil, &resp, true)
+	return resp, err
+}
+
+// AdminListDeletedPets fetches a single page of soft-deleted pets; requires an admin token.
+func (c *Client) AdminListDeletedPets(ctx context.Context, params ListPetsParams) (web.PetPageResponse, error) {
+	var resp web.PetPageResponse
+	err := c.call(ctx, http.MethodGet, "/admin/deleted/pets"+params.query(), nil, &resp, true)
+	return resp, err
+}
+
+// AdminRestorePet restores a deleted pet; requires an admin token.
+func (c *Client) AdminRestorePet(ctx context.Context, id int) (web.PetResponse, error) {
+	var resp web.PetResponse
+	err := c.call(ctx, http.MethodPost, "/admin/deleted/pets/"+strconv.Itoa(id)+"/restore", nil, &resp, true)
+	return resp, err
+}
+
+// AllPets iterates over every pet matching params, fetching pages lazily starting at
+// params.Page (or params.Cursor) and following the cursors from there, so pets added
+// meanwhile are neither skipped nor repeated. Iteration stops after the first error.
+func (c *Client) AllPets(ctx context.Context, params ListPetsParams) iter.Seq2[web.PetResponse, error] {
+	return c.paginate(ctx, params, c.ListPets)
+}
+
+// AdminAllPets is AllPets over /admin/pets.
+func (c *Client) AdminAllPets(ctx context.Context, params ListPetsParams) iter.Seq2[web.PetResponse, error] {
+	return c.paginate(ctx, params, c.AdminListPets)
+}
+
+func (c *Client) paginate(ctx context.Context, params ListPetsParams, fetch func(context.Context, ListPetsParams) (web.PetPageResponse, error)) iter.Seq2[web.PetResponse, error] {
+	return func(yield func(web.PetResponse, error) bool) {
+		for {
+			page, err := fetch(ctx, params)
+			if err != nil {
+				yield(web.PetResponse{}, err)
+				return
+			}
+			for _, pet := range page.Items {
+				if !yield(pet, nil) {
+					return
+				}
+			}
+			if page.NextCursor == "" {
+				return
+			}
+			params.Page = 0
+			params.Cursor = page.NextCursor
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"Go-PetStoreApp/model/web"
+)
+
+func petPhotosPath(petID int) string {
+	return "/pets/" + strconv.Itoa(petID) + "/photos"
+}
+
+// UploadPetPhoto uploads a JPEG, PNG or GIF image as a photo of a pet; primary makes it
+// the pet's primary photo.
+func (c *Client) UploadPetPhoto(ctx context.Context, petID int, filename string, data []byte, primary bool) (web.PetPhotoResponse, error) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	part, err := form.CreateFormFile("photo", filename)
+	if err != nil {
+		return web.PetPhotoResponse{}, err
+	}
+	part.Write(data)
+	if primary {
+		form.WriteField("primary", "true")
+	}
+	if err := form.Close(); err != nil {
+		return web.PetPhotoResponse{}, err
+	}
+
+	var resp web.PetPhotoResponse
+	err = c.call(ctx, http.MethodPost, petPhotosPath(petID), rawBody{contentType: form.FormDataContentType(), data: buf.Bytes()}, &resp, true)
+	return resp, err
+}
+
+// ListPetPhotos returns the photos of a pet in display order.
+func (c *Client) ListPetPhotos(ctx context.Context, petID int) ([]web.PetPhotoResponse, error) {
+	var resp []web.PetPhotoResponse
+	err := c.call(ctx, http.MethodGet, petPhotosPath(petID), nil, &resp, true)
+	return resp, err
+}
+
+// ReorderPetPhotos puts the photos of a pet in the given order; photoIDs must list all of them.
+func (c *Client) ReorderPetPhotos(ctx context.Context, petID int, photoIDs []int) ([]web.PetPhotoResponse, error) {
+	var resp []web.PetPhotoResponse
+	err := c.call(ctx, http.MethodPut, petPhotosPath(petID), web.PetPhotoOrderRequest{PhotoIds: photoIDs}, &resp, true)
+	return resp, err
+}
+
+func (c *Client) SetPrimaryPetPhoto(ctx context.Context, petID, photoID int) ([]web.PetPhotoResponse, error) {
+	var resp []web.PetPhotoResponse
+	err := c.call(ctx, http.MethodPut, petPhotosPath(petID)+"/"+strconv.Itoa(photoID)+"/primary", nil, &resp, true)
+	return resp, err
+}
+
+func (c *Client) DeletePetPhoto(ctx context.Context, petID, photoID int) error {
+	return c.call(ctx, http.MethodDelete, petPhotosPath(petID)+"/"+strconv.Itoa(photoID), nil, nil, true)
+}
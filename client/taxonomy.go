@@ -0,0 +1,23 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"Go-PetStoreApp/model/web"
+)
+
+// ListSpecies returns the species pets can have.
+func (c *Client) ListSpecies(ctx context.Context) ([]web.SpeciesResponse, error) {
+	var resp []web.SpeciesResponse
+	err := c.call(ctx, http.MethodGet, "/species", nil, &resp, true)
+	return resp, err
+}
+
+// ListBreeds returns the breeds of a species.
+func (c *Client) ListBreeds(ctx context.Context, speciesID int) ([]web.BreedResponse, error) {
+	var resp []web.BreedResponse
+	err := c.call(ctx, http.MethodGet, "/species/"+strconv.Itoa(speciesID)+"/breeds", nil, &resp, true)
+	return resp, err
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"Go-PetStoreApp/model/web"
+)
+
+// Register creates an account and stores the returned token.
+func (c *Client) Register(ctx context.Context, req web.UserRegisterRequest) (web.AuthResponse, error) {
+	var resp web.AuthResponse
+	if err := c.call(ctx, http.MethodPost, "/users/register", req, &resp, false); err != nil {
+		return web.AuthResponse{}, err
+	}
+	c.SetToken(resp.Token)
+	return resp, nil
+}
+
+// Login authenticates and stores the returned token.
+func (c *Client) Login(ctx context.Context, req web.UserLoginRequest) (web.AuthResponse, error) {
+	var resp web.AuthResponse
+	if err := c.call(ctx, http.MethodPost, "/users/login", req, &resp, false); err != nil {
+		return web.AuthResponse{}, err
+	}
+	c.SetToken(resp.Token)
+	return resp, nil
+}
+
+// RefreshToken exchanges the current token for a new one and stores it.
+func (c *Client) RefreshToken(ctx context.Context) (web.AuthResponse, error) {
+	var resp web.AuthResponse
+	if err := c.do(ctx, http.MethodPost, "/auth/refresh", nil, &resp, true); err != nil {
+		return web.AuthResponse{}, err
+	}
+	c.SetToken(resp.Token)
+	return resp, nil
+}
+
+func (c *Client) GetUser(ctx context.Context, id int) (web.UserResponse, error) {
+	var resp web.UserResponse
+	err := c.call(ctx, http.MethodGet, "/users/"+strconv.Itoa(id), nil, &resp, true)
+	return resp, err
+}
+
+func (c *Client) ListUsers(ctx context.Context) ([]web.UserResponse, error) {
+	var resp []web.UserResponse
+	err := c.call(ctx, http.MethodGet, "/users", nil, &resp, true)
+	return resp, err
+}
+
+// UpdateUser and ChangePassword send req.IfMatch as If-Match, like UpdatePet.
+func (c *Client) UpdateUser(ctx context.Context, id int, req web.UserUpdateRequest) (web.UserResponse, error) {
+	var resp web.UserResponse
+	err := c.call(ctx, http.MethodPut, "/users/"+strconv.Itoa(id), conditional{req, req.IfMatch}, &resp, true)
+	return resp, err
+}
+
+// PatchUser changes only the fields in req.Patch, as in PatchPet.
+func (c *Client) PatchUser(ctx context.Context, req web.PatchRequest) (web.UserResponse, error) {
+	var resp web.UserResponse
+	body := rawBody{contentType: req.ContentType, data: req.Patch}
+	err := c.call(ctx, http.MethodPatch, "/users/"+strconv.Itoa(req.Id), conditional{body, req.IfMatch}, &resp, true)
+	return resp, err
+}
+
+func (c *Client) ChangePassword(ctx context.Context, id int, req web.UserChangePasswordRequest) error {
+	return c.call(ctx, http.MethodPatch, "/users/"+strconv.Itoa(id)+"/password", conditional{req, req.IfMatch}, nil, true)
+}
+
+// DeleteUser deletes the account; a non-empty ifMatch makes it conditional.
+func (c *Client) DeleteUser(ctx context.Context, id int, ifMatch string) error {
+	return c.call(ctx, http.MethodDelete, "/users/"+strconv.Itoa(id), conditional{nil, ifMatch}, nil, true)
+}
+
+// AdminListUsers requires an admin token.
+func (c *Client) AdminListUsers(ctx context.Context) ([]web.UserResponse, error) {
+	var resp []web.UserResponse
+	err := c.call(ctx, http.MethodGet, "/admin/users", nil, &resp, true)
+	return resp, err
+}
+
+// AdminListDeletedUsers returns the soft-deleted users; requires an admin token.
+func (c *Client) AdminListDeletedUsers(ctx context.Context) ([]web.UserResponse, error) {
+	var resp []web.UserResponse
+	err := c.call(ctx, http.MethodGet, "/admin/deleted/users", nil, &resp, true)
+	return resp, err
+}
+
+// AdminRestoreUser restores a deleted user and the pets deleted with them; requires an
+// admin token.
+func (c *Client) AdminRestoreUser(ctx context.Context, id int) (web.UserResponse, error) {
+	var resp web.UserResponse
+	err := c.call(ctx, http.MethodPost, "/admin/deleted/users/"+strconv.Itoa(id)+"/restore", nil, &resp, true)
+	return resp, err
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"Go-PetStoreApp/app"
+	"Go-PetStoreApp/migrations"
+	"Go-PetStoreApp/repository"
+	"Go-PetStoreApp/seed"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gopkg.in/yaml.v3"
+)
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, `usage: petstore [command] [flags]
+
+commands:
+  serve          run the API server (default)
+  config print   print the effective configuration with secrets redacted
+  users create --username NAME --email EMAIL [--password PW] [--admin]
+  users set-role --id ID --role user|admin
+  users reset-password --id ID [--password PW]
+  users list
+  pets reassign --from USER_ID --to USER_ID
+  tokens revoke --user USER_ID
+  deleted purge [--older-than DURATION]
+                 hard-delete users and pets deleted longer ago than SOFT_DELETE_RETENTION
+  seed [--users N] [--pets N] [--seed N] [--reset]
+                 fill the database with deterministic development fixtures
+  help           show this message
+
+Configuration is read from defaults, --config FILE (or CONFIG_FILE), environment
+variables and flags, later sources winning. Run "petstore serve -h" for all flags.
+Admin commands use the same configuration and database as the server and accept
+--output table|json.
+`)
+}
+
+// loadConfig loads and validates the configuration for a command. On failure it reports
+// every problem and returns a nil config with the exit code.
+func loadConfig(name string, args []string) (*app.Config, int) {
+	cfg, err := app.LoadConfig(name, args, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil, 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, 2
+	}
+	return cfg, 0
+}
+
+func configCommand(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: petstore config print [flags]")
+		return 2
+	}
+	// an invalid configuration is still printed: seeing it is usually how you fix it
+	cfg, err := app.LoadConfig("config print", args[1:], os.Stderr)
+	var cfgErr *app.ConfigError
+	if err != nil && !errors.As(err, &cfgErr) {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if cfgErr != nil {
+		defer fmt.Fprintln(os.Stderr, cfgErr)
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	defer enc.Close()
+	if err := enc.Encode(cfg.Redacted()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if cfgErr != nil {
+		return 1
+	}
+	return 0
+}
+
+func seedCommand(args []string) int {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	opts := seed.Options{}
+	fs.Int64Var(&opts.Seed, "seed", 1, "random seed; the same seed produces the same data")
+	fs.IntVar(&opts.Users, "users", 50, "number of users")
+	fs.IntVar(&opts.Pets, "pets", 500, "number of pets, spread over the users")
+	fs.StringVar(&opts.Password, "password", "password123", "password of every seeded account")
+	fs.BoolVar(&opts.Reset, "reset", false, "delete ALL pets and users first")
+	format := fs.String("output", "table", "output format: table or json")
+	configFlags := app.RegisterConfigFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "--output must be table or json\n")
+		return 2
+	}
+	cfg, err := configFlags.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	db := app.NewDB(cfg)
+	defer db.Close()
+	if cfg.AutoMigrate {
+		if _, err := migrations.Apply(ctx, db); err != nil {
+			fmt.Fprintln(os.Stderr, "error: applying migrations:", err)
+			return 1
+		}
+	}
+
+	opts.Progress = func(done, total int) {
+		if done%100 == 0 || done == total {
+			fmt.Fprintf(os.Stderr, "\rseeding users %d/%d", done, total)
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		}
+	}
+	blobStore, _, err := app.NewBlobStore(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error: opening photo storage:", err)
+		return 1
+	}
+	seeder := seed.NewSeeder(db, repository.NewUserRepository(), repository.NewPetRepository(), repository.NewPetPhotoRepository(), blobStore)
+	result, err := seeder.Run(ctx, opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	out := &output{w: os.Stdout, json: *format == "json"}
+	if err := out.result(result, fmt.Sprintf("users created: %d, skipped (already present): %d, pets created: %d\nadmin login: %s / %s",
+		result.UsersCreated, result.UsersSkipped, result.PetsCreated, seed.AdminUsername, opts.Password)); err != nil {
+		return 1
+	}
+	return 0
+}
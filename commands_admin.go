@@ -0,0 +1,325 @@
+package main
+
+import (
+	"Go-PetStoreApp/app"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/migrations"
+	"Go-PetStoreApp/model/web"
+	"Go-PetStoreApp/repository"
+	"Go-PetStoreApp/search"
+	"Go-PetStoreApp/service"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// adminCommand is one "group action" subcommand. setup registers its flags and returns the
+// function that runs it once the services are open.
+type adminCommand struct {
+	usage string
+	setup func(fs *flag.FlagSet) func(ctx context.Context, a *adminApp, out *output) error
+}
+
+var adminCommands = map[string]map[string]adminCommand{
+	"users": {
+		"create":         {"users create --username NAME --email EMAIL [--password PW] [--admin]", usersCreate},
+		"set-role":       {"users set-role --id ID --role user|admin", usersSetRole},
+		"reset-password": {"users reset-password --id ID [--password PW]", usersResetPassword},
+		"list":           {"users list", usersList},
+	},
+	"pets": {
+		"reassign": {"pets reassign --from USER_ID --to USER_ID", petsReassign},
+	},
+	"tokens": {
+		"revoke": {"tokens revoke --user USER_ID", tokensRevoke},
+	},
+	"deleted": {
+		"purge": {"deleted purge [--older-than DURATION]", deletedPurge},
+	},
+}
+
+// adminApp is the service layer wired to the configured database, as in the server.
+type adminApp struct {
+	db               *sql.DB
+	userService      service.UserService
+	petService       service.PetService
+	retentionService service.RetentionService
+	retention        time.Duration
+}
+
+func openAdminApp(ctx context.Context, cfg *app.Config) (*adminApp, error) {
+	db := app.NewDB(cfg)
+	if cfg.AutoMigrate {
+		if _, err := migrations.Apply(ctx, db); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("applying migrations: %w", err)
+		}
+	}
+	validate := validator.New()
+	jwt := helper.NewJWT(cfg.JWTSecretKey, cfg.TokenExpiry)
+	store, _, err := app.NewBlobStore(cfg)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening photo storage: %w", err)
+	}
+	userRepo, petRepo, photoRepo := repository.NewUserRepository(), repository.NewPetRepository(), repository.NewPetPhotoRepository()
+	auditRepo := repository.NewAuditRepository()
+	// the in-memory search index only lives in the server, so commands don't feed it
+	engine := search.NewPostgres()
+	return &adminApp{
+		db:               db,
+		userService:      service.NewUserService(userRepo, petRepo, auditRepo, db, validate, jwt, engine),
+		petService:       service.NewPetService(petRepo, userRepo, repository.NewTaxonomyRepository(), photoRepo, auditRepo, db, validate, engine, store),
+		retentionService: service.NewRetentionService(userRepo, petRepo, photoRepo, auditRepo, store, db),
+		retention:        cfg.SoftDeleteRetention,
+	}, nil
+}
+
+// runAdminCommand parses "<group> <action> [flags]" and runs it against the database.
+func runAdminCommand(group string, args []string) int {
+	actions := adminCommands[group]
+	if len(args) == 0 || actions[args[0]].setup == nil {
+		names := make([]string, 0, len(actions))
+		for name := range actions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "usage:\n")
+		for _, name := range names {
+			fmt.Fprintf(os.Stderr, "  petstore %s [--output table|json]\n", actions[name].usage)
+		}
+		return 2
+	}
+	action := actions[args[0]]
+
+	fs := flag.NewFlagSet(group+" "+args[0], flag.ContinueOnError)
+	format := fs.String("output", "table", "output format: table or json")
+	run := action.setup(fs)
+	configFlags := app.RegisterConfigFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "--output must be table or json\n")
+		return 2
+	}
+	cfg, err := configFlags.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	a, err := openAdminApp(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	defer a.db.Close()
+
+	if err := runRecovered(ctx, a, &output{w: os.Stdout, json: *format == "json"}, run); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
+
+// runRecovered turns the panics the repositories use for database errors into an error.
+func runRecovered(ctx context.Context, a *adminApp, out *output, run func(context.Context, *adminApp, *output) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return run(ctx, a, out)
+}
+
+func usersCreate(fs *flag.FlagSet) func(context.Context, *adminApp, *output) error {
+	username := fs.String("username", "", "username")
+	email := fs.String("email", "", "email address")
+	password := fs.String("password", "", "password; generated and printed when empty")
+	admin := fs.Bool("admin", false, "create an administrator")
+	return func(ctx context.Context, a *adminApp, out *output) error {
+		generated := *password == ""
+		if generated {
+			*password = randomPassword()
+		}
+		role := "user"
+		if *admin {
+			role = "admin"
+		}
+		resp, err := a.userService.Register(ctx, web.UserRegisterRequest{Username: *username, Email: *email, Password: *password, Role: role})
+		if err != nil {
+			return err
+		}
+		result := userResult{UserResponse: resp.User}
+		if generated {
+			result.Password = *password
+		}
+		return out.users([]userResult{result}, generated)
+	}
+}
+
+func usersSetRole(fs *flag.FlagSet) func(context.Context, *adminApp, *output) error {
+	id := fs.Int("id", 0, "user ID")
+	role := fs.String("role", "", "user or admin")
+	return func(ctx context.Context, a *adminApp, out *output) error {
+		user, err := a.userService.SetRole(ctx, *id, web.UserSetRoleRequest{Role: *role})
+		if err != nil {
+			return err
+		}
+		return out.users([]userResult{{UserResponse: user}}, false)
+	}
+}
+
+func usersResetPassword(fs *flag.FlagSet) func(context.Context, *adminApp, *output) error {
+	id := fs.Int("id", 0, "user ID")
+	password := fs.String("password", "", "new password; generated and printed when empty")
+	return func(ctx context.Context, a *adminApp, out *output) error {
+		generated := *password == ""
+		if generated {
+			*password = randomPassword()
+		}
+		if err := a.userService.ResetPassword(ctx, *id, web.UserResetPasswordRequest{NewPassword: *password}); err != nil {
+			return err
+		}
+		user, err := a.userService.FindById(ctx, *id)
+		if err != nil {
+			return err
+		}
+		result := userResult{UserResponse: user}
+		if generated {
+			result.Password = *password
+		}
+		return out.users([]userResult{result}, generated)
+	}
+}
+
+func usersList(*flag.FlagSet) func(context.Context, *adminApp, *output) error {
+	return func(ctx context.Context, a *adminApp, out *output) error {
+		users, err := a.userService.FindAll(ctx)
+		if err != nil {
+			return err
+		}
+		results := make([]userResult, len(users))
+		for i, u := range users {
+			results[i] = userResult{UserResponse: u}
+		}
+		return out.users(results, false)
+	}
+}
+
+func petsReassign(fs *flag.FlagSet) func(context.Context, *adminApp, *output) error {
+	from := fs.Int("from", 0, "current owner's user ID")
+	to := fs.Int("to", 0, "new owner's user ID")
+	return func(ctx context.Context, a *adminApp, out *output) error {
+		// the service checks the new owner; a mistyped source would just move nothing
+		if _, err := a.userService.FindById(ctx, *from); err != nil {
+			return fmt.Errorf("user %d: %w", *from, err)
+		}
+		moved, err := a.petService.ReassignOwner(ctx, *from, *to)
+		if err != nil {
+			return err
+		}
+		return out.result(map[string]int{"from": *from, "to": *to, "reassigned": moved},
+			fmt.Sprintf("reassigned %d pets from user %d to user %d", moved, *from, *to))
+	}
+}
+
+func tokensRevoke(fs *flag.FlagSet) func(context.Context, *adminApp, *output) error {
+	user := fs.Int("user", 0, "user ID whose tokens are revoked")
+	return func(ctx context.Context, a *adminApp, out *output) error {
+		if err := a.userService.RevokeTokens(ctx, *user); err != nil {
+			return err
+		}
+		return out.result(map[string]any{"user_id": *user, "revoked": true},
+			fmt.Sprintf("revoked all tokens of user %d issued before %s", *user, time.Now().Format(time.RFC3339)))
+	}
+}
+
+func deletedPurge(fs *flag.FlagSet) func(context.Context, *adminApp, *output) error {
+	olderThan := fs.Duration("older-than", 0, "purge users and pets deleted longer ago than this (default: SOFT_DELETE_RETENTION)")
+	return func(ctx context.Context, a *adminApp, out *output) error {
+		if *olderThan <= 0 {
+			*olderThan = a.retention
+		}
+		before := time.Now().Add(-*olderThan)
+		result, err := a.retentionService.Purge(ctx, before)
+		if err != nil {
+			return err
+		}
+		return out.result(result, fmt.Sprintf("purged %d users and %d pets deleted before %s", result.Users, result.Pets, before.Format(time.RFC3339)))
+	}
+}
+
+type userResult struct {
+	web.UserResponse
+	Password string `json:"password,omitempty"` // only when generated
+}
+
+type output struct {
+	w    io.Writer
+	json bool
+}
+
+func (o *output) users(users []userResult, withPassword bool) error {
+	if o.json {
+		if len(users) == 1 {
+			return o.encode(users[0])
+		}
+		return o.encode(users)
+	}
+	tw := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+	header := "ID\tUSERNAME\tEMAIL\tROLE\tCREATED"
+	if withPassword {
+		header += "\tPASSWORD"
+	}
+	fmt.Fprintln(tw, header)
+	for _, u := range users {
+		line := strconv.Itoa(u.Id) + "\t" + u.Username + "\t" + u.Email + "\t" + u.Role + "\t" + u.CreatedAt.Format(time.DateTime)
+		if withPassword {
+			line += "\t" + u.Password
+		}
+		fmt.Fprintln(tw, line)
+	}
+	return tw.Flush()
+}
+
+func (o *output) result(v any, text string) error {
+	if o.json {
+		return o.encode(v)
+	}
+	_, err := fmt.Fprintln(o.w, text)
+	return err
+}
+
+func (o *output) encode(v any) error {
+	enc := json.NewEncoder(o.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func randomPassword() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,12 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type AuditController interface {
+	FindAll(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	PetHistory(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
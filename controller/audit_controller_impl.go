@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"Go-PetStoreApp/errorsx"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/middleware"
+	"Go-PetStoreApp/model/web"
+	"Go-PetStoreApp/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// AuditControllerImpl serves the audit log: all of it to admins (enforced by the router),
+// and the history of their own pets to owners.
+type AuditControllerImpl struct {
+	AuditService service.AuditService
+}
+
+func NewAuditController(s service.AuditService) *AuditControllerImpl {
+	return &AuditControllerImpl{AuditService: s}
+}
+
+func (c *AuditControllerImpl) FindAll(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	req, err := parseAuditListRequest(r.URL.Query())
+	if err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	resp, err := c.AuditService.FindAll(r.Context(), req)
+	if err != nil {
+		writeAuditError(w, err)
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: resp})
+}
+
+func (c *AuditControllerImpl) PetHistory(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusUnauthorized, Status: "Unauthorized"})
+		return
+	}
+	petID, ok := pathID(w, params, "petId", "pet")
+	if !ok {
+		return
+	}
+	page, limit, err := auditPaging(r.URL.Query())
+	if err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	resp, err := c.AuditService.PetHistory(r.Context(), petID, userID, page, limit)
+	if err != nil {
+		writeAuditError(w, err)
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: resp})
+}
+
+func auditPaging(q url.Values) (page, limit int, err error) {
+	if page, err = queryInt(q, "page", 1); err != nil {
+		return 0, 0, err
+	}
+	if page <= 0 {
+		page = 1
+	}
+	limit, err = queryInt(q, "limit", web.DefaultAuditPageLimit)
+	return page, limit, err
+}
+
+// parseAuditListRequest reads the filters of the admin audit listing. from and to accept
+// RFC 3339 or a plain date; to is inclusive, like the pet listing's *_to bounds.
+func parseAuditListRequest(q url.Values) (web.AuditListRequest, error) {
+	req := web.AuditListRequest{
+		Action:    strings.ToLower(strings.TrimSpace(q.Get("action"))),
+		Entity:    strings.ToLower(strings.TrimSpace(q.Get("entity"))),
+		RequestID: strings.TrimSpace(q.Get("request_id")),
+	}
+	var err error
+	if req.Page, req.Limit, err = auditPaging(q); err != nil {
+		return req, err
+	}
+	for _, f := range []struct {
+		name string
+		dst  *int
+	}{{"actor_id", &req.ActorID}, {"entity_id", &req.EntityID}} {
+		if !q.Has(f.name) {
+			continue
+		}
+		id, err := strconv.Atoi(q.Get(f.name))
+		if err != nil || id <= 0 {
+			return req, fmt.Errorf("%s must be a positive integer", f.name)
+		}
+		*f.dst = id
+	}
+	for _, f := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &req.From}, {"to", &req.Before}} {
+		if !q.Has(f.name) {
+			continue
+		}
+		t, dateOnly, err := parseTimeOrDate(q.Get(f.name))
+		if err != nil {
+			return req, fmt.Errorf("%s must be a date (2006-01-02) or an RFC 3339 timestamp", f.name)
+		}
+		if f.name == "to" {
+			if dateOnly {
+				t = t.AddDate(0, 0, 1)
+			} else {
+				t = t.Add(time.Microsecond)
+			}
+		}
+		*f.dst = t.UTC()
+	}
+	return req, nil
+}
+
+func writeAuditError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errorsx.ErrValidation):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrNotFound):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusNotFound, Status: "Not Found", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrUnauthorized):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusForbidden, Status: "Forbidden", Data: err.Error()})
+	default:
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusInternalServerError, Status: "Internal Server Error", Data: err.Error()})
+	}
+}
@@ -0,0 +1,13 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type DocsController interface {
+	OpenAPIYAML(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	OpenAPIJSON(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	SwaggerUI(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
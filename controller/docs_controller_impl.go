@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"Go-PetStoreApp/apispec"
+	"Go-PetStoreApp/helper"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/swaggest/swgui"
+	"github.com/swaggest/swgui/v5emb"
+)
+
+type DocsControllerImpl struct {
+	specYAML []byte
+	specJSON []byte
+	prefix   string
+	ui       http.Handler
+}
+
+// NewDocsController publishes spec with its servers entry pointing at publicURL + "/api".
+// Any path prefix in publicURL (for a reverse proxy) is also applied to the Swagger UI links.
+func NewDocsController(spec []byte, publicURL string) *DocsControllerImpl {
+	specYAML, err := apispec.WithServerURL(spec, publicURL+"/api")
+	helper.PanicIfError(err)
+	specJSON, err := apispec.ToJSON(specYAML)
+	helper.PanicIfError(err)
+
+	prefix := ""
+	if u, err := url.Parse(publicURL); err == nil {
+		prefix = strings.TrimSuffix(u.Path, "/")
+	}
+
+	ui := v5emb.NewHandlerWithConfig(swgui.Config{
+		Title:            "Pet Store API",
+		SwaggerJSON:      prefix + "/openapi.json",
+		BasePath:         prefix + "/docs/",
+		InternalBasePath: "/docs/",
+	})
+	return &DocsControllerImpl{specYAML: specYAML, specJSON: specJSON, prefix: prefix, ui: ui}
+}
+
+func (dc *DocsControllerImpl) OpenAPIYAML(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(dc.specYAML)
+}
+
+func (dc *DocsControllerImpl) OpenAPIJSON(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(dc.specJSON)
+}
+
+// SwaggerUI serves the index page and the embedded static assets under /docs/.
+func (dc *DocsControllerImpl) SwaggerUI(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	if r.URL.Path == "/docs" {
+		http.Redirect(w, r, dc.prefix+"/docs/", http.StatusMovedPermanently)
+		return
+	}
+	dc.ui.ServeHTTP(w, r)
+}
@@ -0,0 +1,12 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type HealthController interface {
+	Liveness(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Readiness(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
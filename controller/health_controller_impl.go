@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/migrations"
+	"Go-PetStoreApp/model/web"
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// dbPingTimeout bounds each readiness probe's database round trip.
+const dbPingTimeout = 2 * time.Second
+
+type HealthControllerImpl struct {
+	DB           *sql.DB
+	JWT          *helper.JWT
+	shuttingDown atomic.Bool
+}
+
+func NewHealthController(db *sql.DB, jwt *helper.JWT) *HealthControllerImpl {
+	return &HealthControllerImpl{DB: db, JWT: jwt}
+}
+
+// MarkShuttingDown makes readiness fail so load balancers stop routing new traffic here.
+func (hc *HealthControllerImpl) MarkShuttingDown() {
+	hc.shuttingDown.Store(true)
+}
+
+// Liveness only reports that the process is serving requests.
+func (hc *HealthControllerImpl) Liveness(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: web.HealthResponse{Status: "ok"}})
+}
+
+// Readiness reports whether this instance should receive traffic.
+func (hc *HealthControllerImpl) Readiness(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	checks := map[string]web.HealthCheck{
+		"shutdown":   hc.checkShutdown(),
+		"database":   hc.checkDatabase(r.Context()),
+		"migrations": hc.checkMigrations(r.Context()),
+		"jwt":        hc.checkJWT(),
+	}
+
+	resp := web.HealthResponse{Status: "ok", Checks: checks}
+	code := http.StatusOK
+	for _, c := range checks {
+		if c.Status != "ok" {
+			resp.Status = "unavailable"
+			code = http.StatusServiceUnavailable
+		}
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: code, Status: http.StatusText(code), Data: resp})
+}
+
+func (hc *HealthControllerImpl) checkShutdown() web.HealthCheck {
+	if hc.shuttingDown.Load() {
+		return web.HealthCheck{Status: "failing", Error: "shutting down"}
+	}
+	return web.HealthCheck{Status: "ok"}
+}
+
+func (hc *HealthControllerImpl) checkDatabase(ctx context.Context) web.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, dbPingTimeout)
+	defer cancel()
+	start := time.Now()
+	err := hc.DB.PingContext(ctx)
+	check := web.HealthCheck{Status: "ok", DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		check.Status = "failing"
+		check.Error = err.Error()
+	}
+	return check
+}
+
+func (hc *HealthControllerImpl) checkMigrations(ctx context.Context) web.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, dbPingTimeout)
+	defer cancel()
+	current, err := migrations.Current(ctx, hc.DB)
+	if err != nil {
+		return web.HealthCheck{Status: "failing", Error: err.Error()}
+	}
+	expected := migrations.Latest()
+	// a newer schema is fine: it happens while a rolling deploy is migrating ahead of us
+	if current < expected {
+		return web.HealthCheck{Status: "failing", Error: fmt.Sprintf("schema at version %d, expected %d", current, expected)}
+	}
+	return web.HealthCheck{Status: "ok"}
+}
+
+func (hc *HealthControllerImpl) checkJWT() web.HealthCheck {
+	if err := hc.JWT.CheckKey(); err != nil {
+		return web.HealthCheck{Status: "failing", Error: err.Error()}
+	}
+	return web.HealthCheck{Status: "ok"}
+}
@@ -0,0 +1,12 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type LogController interface {
+	GetLevel(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	SetLevel(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
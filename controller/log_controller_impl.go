@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/logx"
+	"Go-PetStoreApp/model/web"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// LogControllerImpl lets admins change the process log level without a restart.
+type LogControllerImpl struct{}
+
+func NewLogController() *LogControllerImpl {
+	return &LogControllerImpl{}
+}
+
+func (lc *LogControllerImpl) GetLevel(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: currentLogLevel()})
+}
+
+func (lc *LogControllerImpl) SetLevel(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req web.LogLevelRequest
+	if err := helper.ReadFromRequestBody(r, &req); err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	previous := currentLogLevel()
+	if err := logx.SetLevel(req.Level); err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	logx.FromContext(r.Context()).Warn("log level changed", "from", previous.Level, "to", req.Level)
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: currentLogLevel()})
+}
+
+func currentLogLevel() web.LogLevelResponse {
+	return web.LogLevelResponse{Level: strings.ToLower(logx.Level.Level().String())}
+}
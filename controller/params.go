@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/model/web"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// pathID reads the ID path parameter name and answers 400 when it is not a positive integer,
+// instead of going on with ID 0. entity names it in the message, e.g. "Invalid pet ID".
+func pathID(w http.ResponseWriter, params httprouter.Params, name, entity string) (int, bool) {
+	id, err := strconv.Atoi(params.ByName(name))
+	if err != nil || id <= 0 {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: "Invalid " + entity + " ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+// queryInt reads an optional integer query parameter; def is returned when it is absent.
+func queryInt(q url.Values, name string, def int) (int, error) {
+	if !q.Has(name) {
+		return def, nil
+	}
+	n, err := strconv.Atoi(q.Get(name))
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer", name)
+	}
+	return n, nil
+}
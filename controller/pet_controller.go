@@ -9,7 +9,11 @@ import (
 type PetController interface {
 	Create(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	Update(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Patch(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	Delete(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	FindById(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	FindAll(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Search(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	FindDeleted(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Restore(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 }
\ No newline at end of file
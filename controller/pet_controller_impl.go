@@ -1,22 +1,30 @@
 package controller
 
 import (
+	"Go-PetStoreApp/errorsx"
 	"Go-PetStoreApp/helper"
 	"Go-PetStoreApp/middleware"
 	"Go-PetStoreApp/model/web"
 	"Go-PetStoreApp/service"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 )
 
 type PetControllerImpl struct {
 	PetService service.PetService
+	Cursors    *helper.Cursors
 }
 
-func NewPetController(s service.PetService) *PetControllerImpl {
-	return &PetControllerImpl{PetService: s}
+func NewPetController(s service.PetService, cursors *helper.Cursors) *PetControllerImpl {
+	return &PetControllerImpl{PetService: s, Cursors: cursors}
 }
 
 func (p *PetControllerImpl) Create(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -33,6 +41,14 @@ func (p *PetControllerImpl) Create(w http.ResponseWriter, r *http.Request, _ htt
 	}
 
 	petResp, err := p.PetService.Create(r.Context(), req, userID)
+	if errors.Is(err, errorsx.ErrValidation) {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	if errors.Is(err, errorsx.ErrConflict) {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusConflict, Status: "Conflict", Data: err.Error()})
+		return
+	}
 	if err != nil {
 		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusInternalServerError, Status: "Internal Server Error", Data: err.Error()})
 		return
@@ -42,56 +58,157 @@ func (p *PetControllerImpl) Create(w http.ResponseWriter, r *http.Request, _ htt
 }
 
 func (p *PetControllerImpl) FindAll(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	p.list(w, r, false)
+}
+
+// FindDeleted lists soft-deleted pets for admins, with the filters and paging of FindAll.
+func (p *PetControllerImpl) FindDeleted(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	p.list(w, r, true)
+}
+
+func (p *PetControllerImpl) list(w http.ResponseWriter, r *http.Request, deleted bool) {
 	q := r.URL.Query()
-	page, _ := strconv.Atoi(q.Get("page"))
-	limit, _ := strconv.Atoi(q.Get("limit"))
-	species := q.Get("species")
-	ownerParam := q.Get("owner_id") // admin can pass owner_id to filter
+	req, err := parsePetListRequest(q)
+	if err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	req.Deleted = deleted
+	if raw := q.Get("cursor"); raw != "" {
+		req.Cursor = &web.PetCursor{}
+		if err := p.Cursors.Decode(raw, req.Cursor); err != nil {
+			helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+			return
+		}
+	}
+	userID, ok := listOwner(w, r)
+	if !ok {
+		return
+	}
+	req.OwnerID = userID
 
-	if page <= 0 {
-		page = 1
+	page, err := p.PetService.FindAllByUser(r.Context(), req)
+	if errors.Is(err, errorsx.ErrValidation) {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
 	}
-	if limit <= 0 {
-		limit = 10
+	if err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusInternalServerError, Status: "Internal Server Error", Data: err.Error()})
+		return
 	}
 
+	resp := web.PetPageResponse{
+		Items: page.Items,
+		Limit: req.Limit,
+		Total: page.Total,
+	}
+	if page.Next != nil {
+		resp.NextCursor = p.Cursors.Encode(page.Next)
+	}
+	if page.Prev != nil {
+		resp.PrevCursor = p.Cursors.Encode(page.Prev)
+	}
+	// links keep the client's pagination style; both carry the other query parameters
+	link := func(key, value string) string {
+		next := r.URL.Query()
+		next.Del("page")
+		next.Del("cursor")
+		next.Set(key, value)
+		return r.URL.Path + "?" + next.Encode()
+	}
+	if req.Cursor == nil && q.Has("page") {
+		if req.Page <= 0 {
+			req.Page = 1
+		}
+		resp.Page = req.Page
+		if page.Next != nil {
+			resp.Links.Next = link("page", strconv.Itoa(req.Page+1))
+		}
+		if req.Page > 1 {
+			resp.Links.Prev = link("page", strconv.Itoa(req.Page-1))
+		}
+	} else {
+		if req.Cursor == nil {
+			resp.Page = 1
+		}
+		if page.Next != nil {
+			resp.Links.Next = link("cursor", resp.NextCursor)
+		}
+		if page.Prev != nil {
+			resp.Links.Prev = link("cursor", resp.PrevCursor)
+		}
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: resp})
+}
+
+
+func (p *PetControllerImpl) Search(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	q := r.URL.Query()
+	req := web.PetSearchRequest{Query: strings.TrimSpace(q.Get("q"))}
+	var err error
+	if req.Page, err = queryInt(q, "page", 1); err == nil {
+		req.Limit, err = queryInt(q, "limit", web.DefaultPetPageLimit)
+	}
+	if err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	userID, ok := listOwner(w, r)
+	if !ok {
+		return
+	}
+	req.OwnerID = userID
+
+	resp, err := p.PetService.Search(r.Context(), req)
+	if errors.Is(err, errorsx.ErrValidation) {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	if err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusInternalServerError, Status: "Internal Server Error", Data: err.Error()})
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: resp})
+}
+
+// listOwner returns whose pets a listing or search covers: the caller's own, or for admins
+// those of ?owner_id, or everyone's (0) when it is omitted. It answers 401 without a user
+// and 400 for a malformed owner_id, which must not widen the listing to every owner.
+func listOwner(w http.ResponseWriter, r *http.Request) (int, bool) {
 	role, _ := middleware.GetRoleFromContext(r.Context())
 	// default: user returns only their pets
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok && role != "admin" {
 		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusUnauthorized, Status: "Unauthorized"})
-		return
+		return 0, false
 	}
 
 	// Allow admin to pass owner_id to view specific user; if owner_id omitted and admin, set userID=0 to get all
 	if role == "admin" {
-		if ownerParam != "" {
-			parsedOwner, _ := strconv.Atoi(ownerParam)
+		if ownerParam := r.URL.Query().Get("owner_id"); ownerParam != "" {
+			parsedOwner, err := strconv.Atoi(ownerParam)
+			if err != nil || parsedOwner <= 0 {
+				helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: "owner_id must be a positive integer"})
+				return 0, false
+			}
 			userID = parsedOwner
 		} else {
 			// userID == 0 signals repository to ignore owner filter and return all
 			userID = 0
 		}
 	}
-
-	petsResp, total, err := p.PetService.FindAllByUser(r.Context(), userID, page, limit, species)
-	if err != nil {
-		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusInternalServerError, Status: "Internal Server Error", Data: err.Error()})
-		return
-	}
-
-	resp := map[string]interface{}{
-		"items": petsResp,
-		"page":  page,
-		"limit": limit,
-		"total": total,
-	}
-	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: resp})
+	return userID, true
 }
 
-
 func (p *PetControllerImpl) FindById(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	petID, _ := strconv.Atoi(params.ByName("petId"))
+	petID, ok := pathID(w, params, "petId", "pet")
+	if !ok {
+		return
+	}
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
 		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusUnauthorized, Status: "Unauthorized"})
@@ -103,17 +220,24 @@ func (p *PetControllerImpl) FindById(w http.ResponseWriter, r *http.Request, par
 		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusNotFound, Status: "Not Found", Data: err.Error()})
 		return
 	}
+	if helper.NotModified(w, r, petResp.Version) {
+		return
+	}
 	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: petResp})
 }
 
 func (p *PetControllerImpl) Update(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	petId, _ := strconv.Atoi(params.ByName("petId"))
+	petId, ok := pathID(w, params, "petId", "pet")
+	if !ok {
+		return
+	}
 	var req web.PetUpdateRequest
 	if err := helper.ReadFromRequestBody(r, &req); err != nil {
 		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
 		return
 	}
 	req.Id = petId
+	req.IfMatch = r.Header.Get("If-Match")
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
@@ -122,26 +246,239 @@ func (p *PetControllerImpl) Update(w http.ResponseWriter, r *http.Request, param
 	}
 
 	petResp, err := p.PetService.Update(r.Context(), req, userID)
+	if errors.Is(err, errorsx.ErrValidation) {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	if errors.Is(err, errorsx.ErrConflict) {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusConflict, Status: "Conflict", Data: err.Error()})
+		return
+	}
+	if errors.Is(err, errorsx.ErrPreconditionFailed) {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusPreconditionFailed, Status: "Precondition Failed", Data: err.Error()})
+		return
+	}
 	if err != nil {
 		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusForbidden, Status: "Forbidden", Data: err.Error()})
 		return
 	}
 
+	w.Header().Set("ETag", helper.ETag(petResp.Version))
 	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: petResp})
 }
 
+// Patch updates only the fields of a merge patch or JSON Patch body.
+func (p *PetControllerImpl) Patch(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	petId, ok := pathID(w, params, "petId", "pet")
+	if !ok {
+		return
+	}
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusUnauthorized, Status: "Unauthorized"})
+		return
+	}
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+
+	petResp, err := p.PetService.Patch(r.Context(), web.PatchRequest{
+		Id:          petId,
+		IfMatch:     r.Header.Get("If-Match"),
+		ContentType: r.Header.Get("Content-Type"),
+		Patch:       patch,
+	}, userID)
+	switch {
+	case errors.Is(err, errorsx.ErrValidation):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrNotFound):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusNotFound, Status: "Not Found", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrUnauthorized):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusForbidden, Status: "Forbidden", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrUnsupported):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusUnsupportedMediaType, Status: "Unsupported Media Type", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrConflict):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusConflict, Status: "Conflict", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrPreconditionFailed):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusPreconditionFailed, Status: "Precondition Failed", Data: err.Error()})
+	case err != nil:
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusInternalServerError, Status: "Internal Server Error", Data: err.Error()})
+	default:
+		w.Header().Set("ETag", helper.ETag(petResp.Version))
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: petResp})
+	}
+}
+
 func (p *PetControllerImpl) Delete(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
-	petId, _ := strconv.Atoi(params.ByName("petId"))
+	petId, ok := pathID(w, params, "petId", "pet")
+	if !ok {
+		return
+	}
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
 		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusUnauthorized, Status: "Unauthorized"})
 		return
 	}
 
-	if err := p.PetService.Delete(r.Context(), petId, userID); err != nil {
+	err := p.PetService.Delete(r.Context(), petId, userID, r.Header.Get("If-Match"))
+	if errors.Is(err, errorsx.ErrPreconditionFailed) {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusPreconditionFailed, Status: "Precondition Failed", Data: err.Error()})
+		return
+	}
+	if err != nil {
 		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusForbidden, Status: "Forbidden", Data: err.Error()})
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Restore undeletes a pet for admins.
+func (p *PetControllerImpl) Restore(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	petId, ok := pathID(w, params, "petId", "pet")
+	if !ok {
+		return
+	}
+	petResp, err := p.PetService.Restore(r.Context(), petId)
+	if errors.Is(err, errorsx.ErrNotFound) {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusNotFound, Status: "Not Found", Data: err.Error()})
+		return
+	}
+	if errors.Is(err, errorsx.ErrConflict) {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusConflict, Status: "Conflict", Data: err.Error()})
+		return
+	}
+	if err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusInternalServerError, Status: "Internal Server Error", Data: err.Error()})
+		return
+	}
+	w.Header().Set("ETag", helper.ETag(petResp.Version))
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: petResp})
+}
+
+// parsePetListRequest reads the filters, sort and page size of a pet listing. species may be
+// repeated or comma-separated. Date bounds accept RFC 3339 or a plain date; *_to bounds
+// are inclusive, so created_to=2025-01-31 includes that whole day. Weights are in kg.
+func parsePetListRequest(q url.Values) (web.PetListRequest, error) {
+	req := web.PetListRequest{
+		Name:      strings.TrimSpace(q.Get("name")),
+		Sex:       strings.ToLower(strings.TrimSpace(q.Get("sex"))),
+		Color:     strings.TrimSpace(q.Get("color")),
+		Microchip: strings.TrimSpace(q.Get("microchip")),
+		Sort:      q.Get("sort"),
+		Limit:     web.DefaultPetPageLimit,
+	}
+	var err error
+	if req.Page, err = queryInt(q, "page", 0); err != nil {
+		return req, err
+	}
+	if req.Limit, err = queryInt(q, "limit", req.Limit); err != nil {
+		return req, err
+	}
+	if q.Has("total") {
+		withTotal, err := strconv.ParseBool(q.Get("total"))
+		if err != nil {
+			return req, errors.New("total must be true or false")
+		}
+		req.SkipTotal = !withTotal
+	}
+	if q.Has("neutered") {
+		neutered, err := strconv.ParseBool(q.Get("neutered"))
+		if err != nil {
+			return req, errors.New("neutered must be true or false")
+		}
+		req.Neutered = &neutered
+	}
+	for _, value := range q["species"] {
+		for _, species := range strings.Split(value, ",") {
+			// catalog species names are lower case
+			if species = strings.ToLower(strings.TrimSpace(species)); species != "" {
+				req.Species = append(req.Species, species)
+			}
+		}
+	}
+	for _, f := range []struct {
+		name string
+		dst  **float64
+	}{
+		{"min_price", &req.MinPrice}, {"max_price", &req.MaxPrice},
+		{"min_weight", &req.MinWeightKg}, {"max_weight", &req.MaxWeightKg},
+	} {
+		name, dst := f.name, f.dst
+		if !q.Has(name) {
+			continue
+		}
+		value, err := strconv.ParseFloat(q.Get(name), 64)
+		if err != nil || value < 0 {
+			return req, fmt.Errorf("%s must be a non-negative number", name)
+		}
+		*dst = &value
+	}
+	for _, f := range []struct {
+		name string
+		dst  **int
+	}{{"min_age", &req.MinAge}, {"max_age", &req.MaxAge}} {
+		name, dst := f.name, f.dst
+		if !q.Has(name) {
+			continue
+		}
+		years, err := strconv.Atoi(q.Get(name))
+		if err != nil || years < 0 || years > 200 {
+			return req, fmt.Errorf("%s must be a whole number of years", name)
+		}
+		*dst = &years
+	}
+	for _, f := range []struct {
+		name string
+		dst  *time.Time
+	}{{"born_from", &req.BornFrom}, {"born_to", &req.BornBefore}} {
+		name, dst := f.name, f.dst
+		if !q.Has(name) {
+			continue
+		}
+		t, err := time.Parse(time.DateOnly, q.Get(name))
+		if err != nil {
+			return req, fmt.Errorf("%s must be a date (2006-01-02)", name)
+		}
+		if name == "born_to" {
+			t = t.AddDate(0, 0, 1)
+		}
+		*dst = t
+	}
+	for _, f := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"created_from", &req.CreatedFrom}, {"created_to", &req.CreatedBefore},
+		{"updated_from", &req.UpdatedFrom}, {"updated_to", &req.UpdatedBefore},
+	} {
+		name, dst := f.name, f.dst
+		if !q.Has(name) {
+			continue
+		}
+		t, dateOnly, err := parseTimeOrDate(q.Get(name))
+		if err != nil {
+			return req, fmt.Errorf("%s must be a date (2006-01-02) or an RFC 3339 timestamp", name)
+		}
+		if strings.HasSuffix(name, "_to") {
+			// exclusive bound just past the given day or instant (the database keeps microseconds)
+			if dateOnly {
+				t = t.AddDate(0, 0, 1)
+			} else {
+				t = t.Add(time.Microsecond)
+			}
+		}
+		*dst = t.UTC()
+	}
+	return req, nil
+}
+
+func parseTimeOrDate(s string) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.Parse(time.DateOnly, s); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339Nano, s)
+	return t, false, err
+}
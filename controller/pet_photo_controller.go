@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type PetPhotoController interface {
+	Upload(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	FindAll(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Reorder(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	SetPrimary(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Delete(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"Go-PetStoreApp/errorsx"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/middleware"
+	"Go-PetStoreApp/model/web"
+	"Go-PetStoreApp/service"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// multipartOverhead allows for the part headers and boundaries around an upload.
+const multipartOverhead = 64 << 10
+
+type PetPhotoControllerImpl struct {
+	PetPhotoService service.PetPhotoService
+	MaxBytes        int64
+}
+
+// NewPetPhotoController accepts photo files of up to maxBytes.
+func NewPetPhotoController(s service.PetPhotoService, maxBytes int64) *PetPhotoControllerImpl {
+	return &PetPhotoControllerImpl{PetPhotoService: s, MaxBytes: maxBytes}
+}
+
+// Upload reads a multipart/form-data body with the image in the "photo" field and an
+// optional "primary" field. The file is streamed into memory up to the size limit.
+func (c *PetPhotoControllerImpl) Upload(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusUnauthorized, Status: "Unauthorized"})
+		return
+	}
+	req := web.PetPhotoUpload{}
+	if req.PetId, ok = pathID(w, params, "petId", "pet"); !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, c.MaxBytes+multipartOverhead)
+	if err := c.readUpload(r, &req); err != nil {
+		writePetPhotoError(w, err)
+		return
+	}
+
+	photo, err := c.PetPhotoService.Upload(r.Context(), req, userID)
+	if err != nil {
+		writePetPhotoError(w, err)
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusCreated, Status: "Created", Data: photo})
+}
+
+func (c *PetPhotoControllerImpl) readUpload(r *http.Request, req *web.PetPhotoUpload) error {
+	parts, err := r.MultipartReader()
+	if err != nil {
+		return fmt.Errorf("%w: expected a multipart/form-data body: %v", errorsx.ErrValidation, err)
+	}
+	found := false
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			return fmt.Errorf("%w: photos may be at most %d bytes", errorsx.ErrTooLarge, c.MaxBytes)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: reading the upload: %v", errorsx.ErrValidation, err)
+		}
+		switch part.FormName() {
+		case "photo":
+			if found {
+				return fmt.Errorf("%w: upload one photo per request", errorsx.ErrValidation)
+			}
+			found = true
+			req.Data, err = io.ReadAll(io.LimitReader(part, c.MaxBytes+1))
+			if errors.As(err, &tooBig) || int64(len(req.Data)) > c.MaxBytes {
+				return fmt.Errorf("%w: photos may be at most %d bytes", errorsx.ErrTooLarge, c.MaxBytes)
+			}
+			if err != nil {
+				return fmt.Errorf("%w: reading the upload: %v", errorsx.ErrValidation, err)
+			}
+		case "primary":
+			value, _ := io.ReadAll(io.LimitReader(part, 16))
+			if req.Primary, err = strconv.ParseBool(strings.TrimSpace(string(value))); err != nil {
+				return fmt.Errorf("%w: primary must be true or false", errorsx.ErrValidation)
+			}
+		}
+		part.Close()
+	}
+	if !found {
+		return fmt.Errorf("%w: the photo field is required", errorsx.ErrValidation)
+	}
+	return nil
+}
+
+func (c *PetPhotoControllerImpl) FindAll(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusUnauthorized, Status: "Unauthorized"})
+		return
+	}
+	petID, ok := pathID(w, params, "petId", "pet")
+	if !ok {
+		return
+	}
+	photos, err := c.PetPhotoService.FindByPet(r.Context(), petID, userID)
+	if err != nil {
+		writePetPhotoError(w, err)
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: photos})
+}
+
+func (c *PetPhotoControllerImpl) Reorder(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusUnauthorized, Status: "Unauthorized"})
+		return
+	}
+	var req web.PetPhotoOrderRequest
+	if err := helper.ReadFromRequestBody(r, &req); err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	if req.PetId, ok = pathID(w, params, "petId", "pet"); !ok {
+		return
+	}
+	photos, err := c.PetPhotoService.Reorder(r.Context(), req, userID)
+	if err != nil {
+		writePetPhotoError(w, err)
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: photos})
+}
+
+func (c *PetPhotoControllerImpl) SetPrimary(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusUnauthorized, Status: "Unauthorized"})
+		return
+	}
+	petID, ok := pathID(w, params, "petId", "pet")
+	if !ok {
+		return
+	}
+	photoID, ok := pathID(w, params, "photoId", "photo")
+	if !ok {
+		return
+	}
+	photos, err := c.PetPhotoService.SetPrimary(r.Context(), petID, photoID, userID)
+	if err != nil {
+		writePetPhotoError(w, err)
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: photos})
+}
+
+func (c *PetPhotoControllerImpl) Delete(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusUnauthorized, Status: "Unauthorized"})
+		return
+	}
+	petID, ok := pathID(w, params, "petId", "pet")
+	if !ok {
+		return
+	}
+	photoID, ok := pathID(w, params, "photoId", "photo")
+	if !ok {
+		return
+	}
+	if err := c.PetPhotoService.Delete(r.Context(), petID, photoID, userID); err != nil {
+		writePetPhotoError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writePetPhotoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errorsx.ErrValidation):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrNotFound):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusNotFound, Status: "Not Found", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrUnauthorized):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusForbidden, Status: "Forbidden", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrConflict):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusConflict, Status: "Conflict", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrTooLarge):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusRequestEntityTooLarge, Status: "Request Entity Too Large", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrUnsupported):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusUnsupportedMediaType, Status: "Unsupported Media Type", Data: err.Error()})
+	default:
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusInternalServerError, Status: "Internal Server Error", Data: err.Error()})
+	}
+}
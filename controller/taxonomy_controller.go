@@ -0,0 +1,18 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type TaxonomyController interface {
+	FindAllSpecies(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	CreateSpecies(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	UpdateSpecies(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	DeleteSpecies(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	FindBreeds(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	CreateBreed(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	UpdateBreed(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	DeleteBreed(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+}
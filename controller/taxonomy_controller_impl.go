@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"Go-PetStoreApp/errorsx"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/model/web"
+	"Go-PetStoreApp/service"
+	"errors"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// TaxonomyControllerImpl serves the species and breeds catalog: reads for every signed-in
+// user, changes for admins (enforced by the router).
+type TaxonomyControllerImpl struct {
+	TaxonomyService service.TaxonomyService
+}
+
+func NewTaxonomyController(s service.TaxonomyService) *TaxonomyControllerImpl {
+	return &TaxonomyControllerImpl{TaxonomyService: s}
+}
+
+func (t *TaxonomyControllerImpl) FindAllSpecies(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	species, err := t.TaxonomyService.FindAllSpecies(r.Context())
+	if err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: species})
+}
+
+func (t *TaxonomyControllerImpl) CreateSpecies(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req web.SpeciesRequest
+	if err := helper.ReadFromRequestBody(r, &req); err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	species, err := t.TaxonomyService.CreateSpecies(r.Context(), req)
+	if err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusCreated, Status: "Created", Data: species})
+}
+
+func (t *TaxonomyControllerImpl) UpdateSpecies(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	id, ok := pathID(w, params, "speciesId", "species")
+	if !ok {
+		return
+	}
+	var req web.SpeciesRequest
+	if err := helper.ReadFromRequestBody(r, &req); err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	species, err := t.TaxonomyService.UpdateSpecies(r.Context(), id, req)
+	if err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: species})
+}
+
+func (t *TaxonomyControllerImpl) DeleteSpecies(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	id, ok := pathID(w, params, "speciesId", "species")
+	if !ok {
+		return
+	}
+	if err := t.TaxonomyService.DeleteSpecies(r.Context(), id); err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (t *TaxonomyControllerImpl) FindBreeds(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	speciesID, ok := pathID(w, params, "speciesId", "species")
+	if !ok {
+		return
+	}
+	breeds, err := t.TaxonomyService.FindBreeds(r.Context(), speciesID)
+	if err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: breeds})
+}
+
+func (t *TaxonomyControllerImpl) CreateBreed(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	speciesID, ok := pathID(w, params, "speciesId", "species")
+	if !ok {
+		return
+	}
+	var req web.BreedRequest
+	if err := helper.ReadFromRequestBody(r, &req); err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	breed, err := t.TaxonomyService.CreateBreed(r.Context(), speciesID, req)
+	if err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusCreated, Status: "Created", Data: breed})
+}
+
+func (t *TaxonomyControllerImpl) UpdateBreed(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	id, ok := pathID(w, params, "breedId", "breed")
+	if !ok {
+		return
+	}
+	var req web.BreedRequest
+	if err := helper.ReadFromRequestBody(r, &req); err != nil {
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+		return
+	}
+	breed, err := t.TaxonomyService.UpdateBreed(r.Context(), id, req)
+	if err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+	helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusOK, Status: "OK", Data: breed})
+}
+
+func (t *TaxonomyControllerImpl) DeleteBreed(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	id, ok := pathID(w, params, "breedId", "breed")
+	if !ok {
+		return
+	}
+	if err := t.TaxonomyService.DeleteBreed(r.Context(), id); err != nil {
+		writeTaxonomyError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeTaxonomyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errorsx.ErrValidation):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusBadRequest, Status: "Bad Request", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrNotFound):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusNotFound, Status: "Not Found", Data: err.Error()})
+	case errors.Is(err, errorsx.ErrConflict):
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusConflict, Status: "Conflict", Data: err.Error()})
+	default:
+		helper.WriteToResponseBody(w, web.WebResponse{Code: http.StatusInternalServerError, Status: "Internal Server Error", Data: err.Error()})
+	}
+}
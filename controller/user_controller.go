@@ -9,9 +9,12 @@ type UserController interface {
 	Register(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	Login(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	Update(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Patch(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	ChangePassword(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	Delete(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	FindById(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	FindAll(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 	RefreshToken(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	FindDeleted(w http.ResponseWriter, r *http.Request, params httprouter.Params)
+	Restore(w http.ResponseWriter, r *http.Request, params httprouter.Params)
 }
\ No newline at end of file
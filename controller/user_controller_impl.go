@@ -1,28 +1,30 @@
 package controller
 
 import (
+	"Go-PetStoreApp/errorsx"
+	"Go-PetStoreApp/helper"
 	"Go-PetStoreApp/middleware"
 	"Go-PetStoreApp/model/web"
 	"Go-PetStoreApp/service"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
-	"github.com/go-playground/validator"
 	"github.com/julienschmidt/httprouter"
 )
 
+// UserControllerImpl relies on middleware.RequestValidation for payload shape and on
+// the service layer for business validation.
 type UserControllerImpl struct {
 	userService service.UserService
-	validator   *validator.Validate
 }
 
 func NewUserController(userService service.UserService) *UserControllerImpl {
 	return &UserControllerImpl{
 		userService: userService,
-		validator:   validator.New(),
 	}
 }
 
@@ -32,10 +34,6 @@ func (uc *UserControllerImpl) Register(w http.ResponseWriter, r *http.Request, _
 		uc.writeErrorResponse(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
-	if err := uc.validator.Struct(req); err != nil {
-		uc.writeErrorResponse(w, fmt.Sprintf("Validation failed: %v", err), http.StatusBadRequest)
-		return
-	}
 	resp, err := uc.userService.Register(r.Context(), req)
 	if err != nil {
 		uc.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
@@ -50,10 +48,6 @@ func (uc *UserControllerImpl) Login(w http.ResponseWriter, r *http.Request, _ ht
 		uc.writeErrorResponse(w, "Invalid request payload", http.StatusBadRequest)
 		return
 	}
-	if err := uc.validator.Struct(req); err != nil {
-		uc.writeErrorResponse(w, fmt.Sprintf("Validation failed: %v", err), http.StatusBadRequest)
-		return
-	}
 	resp, err := uc.userService.Login(r.Context(), req)
 	if err != nil {
 		uc.writeErrorResponse(w, err.Error(), http.StatusUnauthorized)
@@ -87,21 +81,67 @@ func (uc *UserControllerImpl) Update(w http.ResponseWriter, r *http.Request, par
         return
     }
 
-    if err := uc.validator.Struct(req); err != nil {
-        uc.writeErrorResponse(w, fmt.Sprintf("Validation failed: %v", err), http.StatusBadRequest)
-        return
-    }
+    req.IfMatch = r.Header.Get("If-Match")
 
     // Call service with both ID and request
     resp, err := uc.userService.Update(r.Context(), targetUserID, req)
+    if errors.Is(err, errorsx.ErrPreconditionFailed) {
+        uc.writeErrorResponse(w, err.Error(), http.StatusPreconditionFailed)
+        return
+    }
     if err != nil {
         uc.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
         return
     }
 
+    w.Header().Set("ETag", helper.ETag(resp.Version))
     uc.writeJSONResponse(w, resp, http.StatusOK)
 }
 
+// Patch updates only the fields of a merge patch or JSON Patch body, for the user themselves.
+func (uc *UserControllerImpl) Patch(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	authenticatedUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		uc.writeErrorResponse(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	targetUserID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		uc.writeErrorResponse(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	if authenticatedUserID != targetUserID {
+		uc.writeErrorResponse(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		uc.writeErrorResponse(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := uc.userService.Patch(r.Context(), web.PatchRequest{
+		Id:          targetUserID,
+		IfMatch:     r.Header.Get("If-Match"),
+		ContentType: r.Header.Get("Content-Type"),
+		Patch:       patch,
+	})
+	switch {
+	case errors.Is(err, errorsx.ErrNotFound):
+		uc.writeErrorResponse(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, errorsx.ErrUnsupported):
+		uc.writeErrorResponse(w, err.Error(), http.StatusUnsupportedMediaType)
+	case errors.Is(err, errorsx.ErrConflict):
+		uc.writeErrorResponse(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, errorsx.ErrPreconditionFailed):
+		uc.writeErrorResponse(w, err.Error(), http.StatusPreconditionFailed)
+	case err != nil:
+		uc.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+	default:
+		w.Header().Set("ETag", helper.ETag(resp.Version))
+		uc.writeJSONResponse(w, resp, http.StatusOK)
+	}
+}
 
 func (uc *UserControllerImpl) ChangePassword(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
     authenticatedUserID, ok := middleware.GetUserIDFromContext(r.Context())
@@ -129,13 +169,14 @@ func (uc *UserControllerImpl) ChangePassword(w http.ResponseWriter, r *http.Requ
 
     // inject the user ID from params
     req.Id = targetUserID
+    req.IfMatch = r.Header.Get("If-Match")
 
-    if err := uc.validator.Struct(req); err != nil {
-        uc.writeErrorResponse(w, fmt.Sprintf("Validation failed: %v", err), http.StatusBadRequest)
+    err = uc.userService.ChangePassword(r.Context(), req)
+    if errors.Is(err, errorsx.ErrPreconditionFailed) {
+        uc.writeErrorResponse(w, err.Error(), http.StatusPreconditionFailed)
         return
     }
-
-    if err := uc.userService.ChangePassword(r.Context(), req); err != nil {
+    if err != nil {
         uc.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
         return
     }
@@ -162,7 +203,12 @@ func (uc *UserControllerImpl) Delete(w http.ResponseWriter, r *http.Request, par
         return
     }
 
-    if err := uc.userService.Delete(r.Context(), targetUserID); err != nil {
+    err = uc.userService.Delete(r.Context(), targetUserID, r.Header.Get("If-Match"))
+    if errors.Is(err, errorsx.ErrPreconditionFailed) {
+        uc.writeErrorResponse(w, err.Error(), http.StatusPreconditionFailed)
+        return
+    }
+    if err != nil {
         uc.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
         return
     }
@@ -181,6 +227,9 @@ func (uc *UserControllerImpl) FindById(w http.ResponseWriter, r *http.Request, p
 		uc.writeErrorResponse(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	if helper.NotModified(w, r, resp.Version) {
+		return
+	}
 	uc.writeJSONResponse(w, resp, http.StatusOK)
 }
 
@@ -214,6 +263,37 @@ func (uc *UserControllerImpl) RefreshToken(w http.ResponseWriter, r *http.Reques
 	uc.writeJSONResponse(w, resp, http.StatusOK)
 }
 
+// FindDeleted lists soft-deleted users for admins.
+func (uc *UserControllerImpl) FindDeleted(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	resp, err := uc.userService.FindDeleted(r.Context())
+	if err != nil {
+		uc.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	uc.writeJSONResponse(w, resp, http.StatusOK)
+}
+
+// Restore undeletes a user and the pets deleted with them, for admins.
+func (uc *UserControllerImpl) Restore(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	userID, err := strconv.Atoi(params.ByName("id"))
+	if err != nil {
+		uc.writeErrorResponse(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	resp, err := uc.userService.Restore(r.Context(), userID)
+	switch {
+	case errors.Is(err, errorsx.ErrNotFound):
+		uc.writeErrorResponse(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, errorsx.ErrConflict):
+		uc.writeErrorResponse(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		uc.writeErrorResponse(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.Header().Set("ETag", helper.ETag(resp.Version))
+		uc.writeJSONResponse(w, resp, http.StatusOK)
+	}
+}
+
 // helpers
 func (uc *UserControllerImpl) writeJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
@@ -229,8 +309,9 @@ func (uc *UserControllerImpl) writeErrorResponse(w http.ResponseWriter, message
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 	_ = json.NewEncoder(w).Encode(web.WebResponse{
-		Code:   statusCode,
-		Status: http.StatusText(statusCode),
-		Data:   map[string]string{"error": message},
+		Code:      statusCode,
+		Status:    http.StatusText(statusCode),
+		Data:      map[string]string{"error": message},
+		RequestID: w.Header().Get(helper.RequestIDHeader),
 	})
 }
@@ -7,4 +7,8 @@ var (
 	ErrConflict     = errors.New("conflict")
 	ErrUnauthorized = errors.New("unauthorized")
 	ErrValidation   = errors.New("validation failed")
+	ErrTooLarge     = errors.New("too large")
+	ErrUnsupported  = errors.New("unsupported media type")
+	// ErrPreconditionFailed means an If-Match header no longer names the current version.
+	ErrPreconditionFailed = errors.New("precondition failed")
 )
@@ -1,12 +1,19 @@
 package exception
 
 import (
+	"Go-PetStoreApp/logx"
 	"encoding/json"
 	"net/http"
+	"runtime/debug"
 )
 
 func ErrorHandler(writer http.ResponseWriter, request *http.Request, err interface{}) {
+	logx.FromContext(request.Context()).Error("panic while handling request", "error", err, "stack", string(debug.Stack()))
 	writer.Header().Set("Content-Type", "application/json")
 	writer.WriteHeader(http.StatusInternalServerError)
-	_ = json.NewEncoder(writer).Encode(map[string]string{"error": "internal server error"})
+	body := map[string]string{"error": "internal server error"}
+	if id := logx.RequestID(request.Context()); id != "" {
+		body["request_id"] = id
+	}
+	_ = json.NewEncoder(writer).Encode(body)
 }
@@ -0,0 +1,58 @@
+package helper
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ETag is the entity tag of a user or pet at a version.
+func ETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// IfMatch reports whether an If-Match header allows changing an entity at version: it is
+// absent, "*", or lists the entity's tag. Weak tags never match (RFC 9110 strong comparison).
+func IfMatch(header string, version int) bool {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return true
+	}
+	tag := ETag(version)
+	for _, t := range strings.Split(header, ",") {
+		if strings.TrimSpace(t) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// IfNoneMatch reports whether an If-None-Match header lists the entity's tag at version, or
+// "*", so a GET can answer 304 Not Modified. Weak tags match too (weak comparison).
+func IfNoneMatch(header string, version int) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	tag := ETag(version)
+	for _, t := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(t), "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// NotModified sets the ETag of an entity at version on a GET response and, when the request's
+// If-None-Match lists it, answers 304 Not Modified and returns true.
+func NotModified(w http.ResponseWriter, r *http.Request, version int) bool {
+	w.Header().Set("ETag", ETag(version))
+	if IfNoneMatch(r.Header.Get("If-None-Match"), version) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
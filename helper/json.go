@@ -1,10 +1,14 @@
 package helper
 
 import (
+	"Go-PetStoreApp/model/web"
 	"encoding/json"
 	"net/http"
 )
 
+// RequestIDHeader carries the correlation ID; middleware.RequestID sets it on every response.
+const RequestIDHeader = "X-Request-ID"
+
 func ReadFromRequestBody(r *http.Request, result interface{}) error {
 	decoder := json.NewDecoder(r.Body)
 	return decoder.Decode(result)
@@ -16,6 +20,14 @@ func WriteToResponseBody(w http.ResponseWriter, response interface{}) {
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
+	// use the envelope's code as the HTTP status so clients don't see 200 on errors
+	if resp, ok := response.(web.WebResponse); ok && resp.Code != 0 {
+		if resp.Code >= 400 && resp.RequestID == "" {
+			resp.RequestID = w.Header().Get(RequestIDHeader)
+			response = resp
+		}
+		w.WriteHeader(resp.Code)
+	}
 	encoder := json.NewEncoder(w)
 	if err := encoder.Encode(response); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -2,55 +2,71 @@ package helper
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type JWTClaims struct {
-	UserID int    `json:"user_id"`
+	UserID   int    `json:"user_id"`
 	Username string `json:"username"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken receives role
-func GenerateToken(userID int, email, username, role string, expiryHours int) (string, error) {
-	secret := os.Getenv("JWT_SECRET_KEY")
-	if secret == "" {
-		return "", fmt.Errorf("JWT_SECRET_KEY not set")
-	}
+// JWT signs and validates tokens with the configured secret.
+type JWT struct {
+	secret []byte
+	expiry time.Duration
+}
+
+func NewJWT(secret string, expiryHours int) *JWT {
 	if expiryHours <= 0 {
 		expiryHours = 24
 	}
+	return &JWT{secret: []byte(secret), expiry: time.Duration(expiryHours) * time.Hour}
+}
+
+// CheckKey reports whether signing key material is available.
+func (j *JWT) CheckKey() error {
+	if len(j.secret) == 0 {
+		return fmt.Errorf("JWT secret key not configured")
+	}
+	return nil
+}
+
+// GenerateToken receives role
+func (j *JWT) GenerateToken(userID int, email, username, role string) (string, error) {
+	if err := j.CheckKey(); err != nil {
+		return "", err
+	}
+	now := time.Now()
 	claims := JWTClaims{
-		UserID: userID,
+		UserID:   userID,
 		Username: username,
-		Email:  email,
-		Role:   role,
+		Email:    email,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expiryHours) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.expiry)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "petstore-api",
 		},
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return token.SignedString(j.secret)
 }
 
-func ValidateToken(tokenString string) (*JWTClaims, error) {
-	secret := os.Getenv("JWT_SECRET_KEY")
-	if secret == "" {
-		return nil, fmt.Errorf("JWT_SECRET_KEY not set")
+func (j *JWT) ValidateToken(tokenString string) (*JWTClaims, error) {
+	if err := j.CheckKey(); err != nil {
+		return nil, err
 	}
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method")
 		}
-		return []byte(secret), nil
+		return j.secret, nil
 	})
 	if err != nil {
 		return nil, err
@@ -3,27 +3,147 @@ package helper
 import (
 	"Go-PetStoreApp/model/domain"
 	"Go-PetStoreApp/model/web"
+	"time"
 )
 
 func ToPetResponse(p domain.Pet) web.PetResponse {
-	return web.PetResponse{
-		Id:        p.ID,
-		Name:      p.Name,
-		Species:   p.Species,
-		Price:     p.Price,
-		OwnerId:   p.CreatedBy,
+	resp := web.PetResponse{
+		Id:          p.ID,
+		Name:        p.Name,
+		Species:     p.Species,
+		Breed:       p.Breed,
+		Price:       p.Price,
+		OwnerId:     p.CreatedBy,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+		Sex:         p.Sex,
+		Color:       p.Color,
+		Weight:      p.Weight,
+		WeightUnit:  p.WeightUnit,
+		Neutered:    p.Neutered,
+		Microchip:   p.Microchip,
+		Description: p.Description,
+		Version:     p.Version,
+	}
+	if !p.DateOfBirth.IsZero() {
+		resp.DateOfBirth = p.DateOfBirth.Format(time.DateOnly)
+		resp.Age = PetAge(p.DateOfBirth, time.Now())
+	}
+	if !p.DeletedAt.IsZero() {
+		resp.DeletedAt = &p.DeletedAt
+	}
+	return resp
+}
+
+// ToPetUpdateRequest is the document a PATCH of the pet applies to: the update request
+// that would leave the pet as it is.
+func ToPetUpdateRequest(p domain.Pet) web.PetUpdateRequest {
+	req := web.PetUpdateRequest{
+		Id:      p.ID,
+		Name:    p.Name,
+		Species: p.Species,
+		Breed:   p.Breed,
+		Price:   p.Price,
+		PetProfile: web.PetProfile{
+			Sex:         p.Sex,
+			Color:       p.Color,
+			Weight:      p.Weight,
+			WeightUnit:  p.WeightUnit,
+			Neutered:    p.Neutered,
+			Microchip:   p.Microchip,
+			Description: p.Description,
+		},
+	}
+	if !p.DateOfBirth.IsZero() {
+		req.DateOfBirth = p.DateOfBirth.Format(time.DateOnly)
+	}
+	return req
+}
+
+// PetAge counts the whole months from born to now, as calendar months: a pet born on the
+// 31st turns a month older on the last day of a shorter month.
+func PetAge(born, now time.Time) *web.PetAge {
+	now = now.UTC()
+	months := (now.Year()-born.Year())*12 + int(now.Month()-born.Month())
+	lastDay := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if now.Day() < born.Day() && now.Day() < lastDay {
+		months--
+	}
+	if months < 0 {
+		months = 0
+	}
+	return &web.PetAge{Years: months / 12, Months: months % 12}
+}
+
+// ToPetPhotoResponse links the photo's blobs through url, the blob store's URL method.
+func ToPetPhotoResponse(p domain.PetPhoto, url func(key string) string) web.PetPhotoResponse {
+	return web.PetPhotoResponse{
+		Id:          p.ID,
+		Position:    p.Position,
+		Primary:     p.Primary,
+		ContentType: p.ContentType,
+		Width:       p.Width,
+		Height:      p.Height,
+		URLs: web.PetPhotoURLs{
+			Original:  url(p.OriginalKey),
+			Medium:    url(p.MediumKey),
+			Thumbnail: url(p.ThumbnailKey),
+		},
 		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.UpdatedAt,
 	}
 }
 
 func ToUserResponse(u domain.User) web.UserResponse {
-	return web.UserResponse{
+	resp := web.UserResponse{
 		Id:        u.ID,
 		Username:  u.Username,
 		Email:     u.Email,
 		Role:      u.Role,
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
+		Version:   u.Version,
+	}
+	if !u.DeletedAt.IsZero() {
+		resp.DeletedAt = &u.DeletedAt
+	}
+	return resp
+}
+
+// ToUserUpdateRequest is the document a PATCH of the user applies to, as for pets.
+func ToUserUpdateRequest(u domain.User) web.UserUpdateRequest {
+	return web.UserUpdateRequest{Id: u.ID, Username: u.Username, Email: u.Email}
+}
+
+func ToSpeciesResponse(s domain.Species) web.SpeciesResponse {
+	return web.SpeciesResponse{
+		Id:        s.ID,
+		Name:      s.Name,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+func ToBreedResponse(b domain.Breed) web.BreedResponse {
+	return web.BreedResponse{
+		Id:        b.ID,
+		Species:   b.Species,
+		Name:      b.Name,
+		CreatedAt: b.CreatedAt,
+		UpdatedAt: b.UpdatedAt,
+	}
+}
+
+func ToAuditEntryResponse(e domain.AuditEntry) web.AuditEntryResponse {
+	return web.AuditEntryResponse{
+		Id:         e.ID,
+		OccurredAt: e.OccurredAt,
+		ActorId:    e.ActorID,
+		Action:     e.Action,
+		Entity:     e.Entity,
+		EntityId:   e.EntityID,
+		Before:     e.Before,
+		After:      e.After,
+		RequestId:  e.RequestID,
+		IP:         e.IP,
 	}
 }
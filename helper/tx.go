@@ -1,15 +1,42 @@
 package helper
 
-import "database/sql"
+import (
+	"Go-PetStoreApp/logx"
+	"context"
+	"database/sql"
+)
 
-func CommitOrRollback(tx *sql.Tx) {
-	err := recover()
+// BeginTx starts a transaction and tags the connection's application_name with the request ID
+// for the duration of the transaction, so pg_stat_activity and Postgres logs (%a in
+// log_line_prefix) can be correlated with application logs.
+func BeginTx(ctx context.Context, db *sql.DB) (*sql.Tx, error) {
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
+		return nil, err
+	}
+	if id := logx.RequestID(ctx); id != "" {
+		if _, err := tx.ExecContext(ctx, `SELECT set_config('application_name', $1, true)`, "petstore:"+id); err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// CommitOrRollback ends tx when deferred by a function with a named err result: it rolls
+// back if the function panics or returns an error, and commits otherwise.
+//
+//	defer helper.CommitOrRollback(tx, &err)
+func CommitOrRollback(tx *sql.Tx, err *error) {
+	if p := recover(); p != nil {
 		errorRollback := tx.Rollback()
 		PanicIfError(errorRollback)
-		panic(err)
-	} else {
-		errorCommit := tx.Commit()
-		PanicIfError(errorCommit)
+		panic(p)
+	}
+	if *err != nil {
+		PanicIfError(tx.Rollback())
+		return
 	}
+	errorCommit := tx.Commit()
+	PanicIfError(errorCommit)
 }
\ No newline at end of file
@@ -0,0 +1,111 @@
+// Package imagex decodes uploaded images and produces the variants that are stored, using
+// only the standard library codecs: JPEG, PNG and GIF (first frame) in, JPEG and PNG out.
+// Re-encoding drops EXIF and all other metadata; the EXIF orientation of a JPEG is applied
+// to the pixels first so the picture still shows the right way up.
+package imagex
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+var (
+	ErrUnsupported = errors.New("unsupported image format")
+	ErrTooLarge    = errors.New("image dimensions too large")
+)
+
+// jpegQuality balances size and artifacts for photos.
+const jpegQuality = 85
+
+// Sniff returns the content type of data judged by its leading bytes, never by a file name
+// or a client-supplied header.
+func Sniff(data []byte) (string, error) {
+	switch ct := http.DetectContentType(data); ct {
+	case "image/jpeg", "image/png", "image/gif":
+		return ct, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupported, ct)
+	}
+}
+
+// Decode decodes data into upright RGBA pixels. The dimensions are checked against
+// maxPixels before any pixel is decoded, so small files claiming huge images are refused
+// cheaply.
+func Decode(data []byte, maxPixels int) (*image.RGBA, error) {
+	if _, err := Sniff(data); err != nil {
+		return nil, err
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d is over %d pixels", ErrTooLarge, cfg.Width, cfg.Height, maxPixels)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	if format == "jpeg" {
+		return orient(rgba, jpegOrientation(data)), nil
+	}
+	return rgba, nil
+}
+
+// Fit scales img down, keeping its aspect ratio, so neither side exceeds size. Smaller
+// images are returned unchanged. Each output pixel averages the source pixels it covers.
+func Fit(img *image.RGBA, size int) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	dw, dh := size, h*size/w
+	if h > w {
+		dw, dh = w*size/h, size
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := img.Pix[sy*img.Stride+x0*4 : sy*img.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					r, g, b, a = r+uint64(row[i]), g+uint64(row[i+1]), b+uint64(row[i+2]), a+uint64(row[i+3])
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2], dst.Pix[i+3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+	return dst
+}
+
+// Encode writes img as PNG when it has transparency and as JPEG otherwise, and returns the
+// bytes, content type and file extension.
+func Encode(img *image.RGBA) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if !img.Opaque() {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", "", err
+		}
+		return buf.Bytes(), "image/png", ".png", nil
+	}
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), "image/jpeg", ".jpg", nil
+}
@@ -0,0 +1,129 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// patchCase applies patch to doc; want is the resulting document, or "" when the patch
+// must fail with an error containing wantErr.
+type patchCase struct {
+	name    string
+	doc     string
+	patch   string
+	want    string
+	wantErr string
+}
+
+func TestApply(t *testing.T) {
+	cases := []patchCase{
+		{name: "add member", doc: `{"a":1}`, patch: `[{"op":"add","path":"/b","value":2}]`, want: `{"a":1,"b":2}`},
+		{name: "replace member", doc: `{"a":1}`, patch: `[{"op":"replace","path":"/a","value":2}]`, want: `{"a":2}`},
+		{name: "replace missing member", doc: `{"a":1}`, patch: `[{"op":"replace","path":"/b","value":2}]`, wantErr: `member "b" not found`},
+		{name: "replace root", doc: `{"a":1}`, patch: `[{"op":"replace","path":"","value":[1]}]`, want: `[1]`},
+		{name: "missing value", doc: `{"a":1}`, patch: `[{"op":"add","path":"/b"}]`, wantErr: `missing "value"`},
+		{name: "null value", doc: `{"a":1}`, patch: `[{"op":"add","path":"/b","value":null}]`, want: `{"a":1,"b":null}`},
+		{name: "unknown op", doc: `{}`, patch: `[{"op":"merge","path":"/a","value":1}]`, wantErr: `unknown operation "merge"`},
+
+		{name: "move member", doc: `{"a":{"b":1},"c":{}}`, patch: `[{"op":"move","from":"/a/b","path":"/c/b"}]`, want: `{"a":{},"c":{"b":1}}`},
+		{name: "move onto itself", doc: `{"a":{"b":1}}`, patch: `[{"op":"move","from":"/a","path":"/a"}]`, want: `{"a":{"b":1}}`},
+		{name: "move into itself", doc: `{"a":{"b":1}}`, patch: `[{"op":"move","from":"/a","path":"/a/b/c"}]`, wantErr: "cannot move /a into itself"},
+		{name: "move into sibling with common prefix", doc: `{"a":1,"ab":{}}`, patch: `[{"op":"move","from":"/a","path":"/ab/a"}]`, want: `{"ab":{"a":1}}`},
+		{name: "copy does not share", doc: `{"a":{"b":1}}`, patch: `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, want: `{"a":{"b":1},"c":{"b":2}}`},
+
+		{name: "add at end with -", doc: `{"a":[1,2]}`, patch: `[{"op":"add","path":"/a/-","value":3}]`, want: `{"a":[1,2,3]}`},
+		{name: "add at length", doc: `{"a":[1,2]}`, patch: `[{"op":"add","path":"/a/2","value":3}]`, want: `{"a":[1,2,3]}`},
+		{name: "add in the middle", doc: `{"a":[1,3]}`, patch: `[{"op":"add","path":"/a/1","value":2}]`, want: `{"a":[1,2,3]}`},
+		{name: "add past the end", doc: `{"a":[1,2]}`, patch: `[{"op":"add","path":"/a/3","value":3}]`, wantErr: "index 3 is out of range"},
+		{name: "replace -", doc: `{"a":[1,2]}`, patch: `[{"op":"replace","path":"/a/-","value":3}]`, wantErr: `"-" is not an array index`},
+		{name: "remove -", doc: `{"a":[1,2]}`, patch: `[{"op":"remove","path":"/a/-"}]`, wantErr: `"-" is not an array index`},
+		{name: "test -", doc: `{"a":[1,2]}`, patch: `[{"op":"test","path":"/a/-","value":2}]`, wantErr: `"-" is not an array index`},
+
+		{name: "leading zero index", doc: `{"a":[1,2]}`, patch: `[{"op":"replace","path":"/a/01","value":3}]`, wantErr: `"01" is not an array index`},
+		{name: "zero index", doc: `{"a":[1,2]}`, patch: `[{"op":"replace","path":"/a/0","value":3}]`, want: `{"a":[3,2]}`},
+		{name: "signed index", doc: `{"a":[1,2]}`, patch: `[{"op":"remove","path":"/a/+1"}]`, wantErr: `"+1" is not an array index`},
+		{name: "negative zero index", doc: `{"a":[1,2]}`, patch: `[{"op":"remove","path":"/a/-0"}]`, wantErr: `"-0" is not an array index`},
+		{name: "leading zero member name", doc: `{"a":{"01":1}}`, patch: `[{"op":"remove","path":"/a/01"}]`, want: `{"a":{}}`},
+
+		{name: "escaped slash", doc: `{"a/b":1}`, patch: `[{"op":"replace","path":"/a~1b","value":2}]`, want: `{"a/b":2}`},
+		{name: "escaped tilde", doc: `{"a~b":1}`, patch: `[{"op":"replace","path":"/a~0b","value":2}]`, want: `{"a~b":2}`},
+		{name: "tilde zero then one", doc: `{"~1":1,"/":2}`, patch: `[{"op":"remove","path":"/~01"}]`, want: `{"/":2}`},
+		{name: "pointer without slash", doc: `{"a":1}`, patch: `[{"op":"remove","path":"a"}]`, wantErr: `path "a" must be empty or start with /`},
+
+		{name: "remove root", doc: `{"a":1}`, patch: `[{"op":"remove","path":""}]`, wantErr: "cannot remove the whole document"},
+		{name: "move root", doc: `{"a":1}`, patch: `[{"op":"move","from":"","path":"/b"}]`, wantErr: "into itself"},
+
+		{name: "failed test", doc: `{"a":1}`, patch: `[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`, wantErr: "operation 1 (test): test operation failed: /a"},
+		{name: "failed later op", doc: `{"a":[1]}`, patch: `[{"op":"add","path":"/a/-","value":2},{"op":"remove","path":"/b"}]`, wantErr: `operation 1 (remove): member "b" not found`},
+
+		{name: "integer equals float", doc: `{"a":1}`, patch: `[{"op":"test","path":"/a","value":1.0}]`, want: `{"a":1}`},
+		{name: "exponent equals integer", doc: `{"a":[100]}`, patch: `[{"op":"test","path":"/a","value":[1e2]}]`, want: `{"a":[100]}`},
+		{name: "different numbers", doc: `{"a":1}`, patch: `[{"op":"test","path":"/a","value":1.5}]`, wantErr: "test operation failed"},
+		{name: "large integers", doc: `{"a":9007199254740993}`, patch: `[{"op":"test","path":"/a","value":9007199254740992}]`, wantErr: "test operation failed"},
+		{name: "number is not a string", doc: `{"a":1}`, patch: `[{"op":"test","path":"/a","value":"1"}]`, wantErr: "test operation failed"},
+		{name: "objects ignore order", doc: `{"a":{"x":1,"y":[true,null]}}`, patch: `[{"op":"test","path":"/a","value":{"y":[true,null],"x":1.0}}]`, want: `{"a":{"x":1,"y":[true,null]}}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			doc := []byte(c.doc)
+			got, err := Apply(doc, []byte(c.patch))
+			check(t, got, err, c)
+			if string(doc) != c.doc {
+				t.Errorf("the document changed to %s", doc)
+			}
+		})
+	}
+}
+
+func TestApplyTestFailure(t *testing.T) {
+	_, err := Apply([]byte(`{"a":1}`), []byte(`[{"op":"test","path":"/a","value":2}]`))
+	if !errors.Is(err, ErrTestFailed) {
+		t.Fatalf("got %v, want ErrTestFailed", err)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	cases := []patchCase{
+		{name: "replace member", doc: `{"a":1,"b":2}`, patch: `{"a":3}`, want: `{"a":3,"b":2}`},
+		{name: "remove member", doc: `{"a":1,"b":2}`, patch: `{"a":null}`, want: `{"b":2}`},
+		{name: "remove missing member", doc: `{"a":1}`, patch: `{"b":null}`, want: `{"a":1}`},
+		{name: "nested null", doc: `{"a":{"b":1,"c":2}}`, patch: `{"a":{"b":null,"d":3}}`, want: `{"a":{"c":2,"d":3}}`},
+		{name: "null inside a new object", doc: `{"a":1}`, patch: `{"a":{"b":null,"c":2}}`, want: `{"a":{"c":2}}`},
+		{name: "arrays are replaced", doc: `{"a":[1,2]}`, patch: `{"a":[3]}`, want: `{"a":[3]}`},
+		{name: "non-object patch", doc: `{"a":1}`, patch: `[1]`, want: `[1]`},
+		{name: "null patch", doc: `{"a":1}`, patch: `null`, want: `null`},
+		{name: "invalid patch", doc: `{"a":1}`, patch: `{"a":`, wantErr: "patch: "},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := MergePatch([]byte(c.doc), []byte(c.patch))
+			check(t, got, err, c)
+		})
+	}
+}
+
+func check(t *testing.T, got []byte, err error, c patchCase) {
+	t.Helper()
+	if c.wantErr != "" {
+		if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+			t.Fatalf("got %s, %v; want an error containing %q", got, err, c.wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotValue, wantValue any
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("result %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(c.want), &wantValue); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got %s, want %s", got, c.want)
+	}
+}
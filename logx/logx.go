@@ -0,0 +1,122 @@
+// Package logx configures log/slog for the service and carries request-scoped loggers
+// through context.Context.
+package logx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Level is the process-wide minimum level; it can be changed while running.
+var Level = new(slog.LevelVar)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute names (case-insensitive, matched as substrings) whose values never reach the logs.
+var sensitiveKeys = []string{"password", "token", "authorization", "secret", "cookie"}
+
+// Setup installs the default slog logger. format is "json" or "text".
+func Setup(format, level string) error {
+	return SetupWriter(os.Stderr, format, level)
+}
+
+func SetupWriter(w io.Writer, format, level string) error {
+	if err := SetLevel(level); err != nil {
+		return err
+	}
+	opts := &slog.HandlerOptions{Level: Level, ReplaceAttr: redact}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", "text":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// SetLevel parses "debug", "info", "warn" or "error" and applies it.
+func SetLevel(level string) error {
+	if level == "" {
+		level = "info"
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("unknown log level %q", level)
+	}
+	Level.Set(l)
+	return nil
+}
+
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if IsSensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	if h, ok := a.Value.Any().(http.Header); ok {
+		return slog.Any(a.Key, RedactHeader(h))
+	}
+	return a
+}
+
+// IsSensitive reports whether a field or header name holds credentials.
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactHeader returns a copy of h with credential-bearing headers masked.
+func RedactHeader(h http.Header) http.Header {
+	out := h.Clone()
+	for k := range out {
+		if IsSensitive(k) {
+			out[k] = []string{redacted}
+		}
+	}
+	return out
+}
+
+type ctxKey struct{}
+
+// FromContext returns the request-scoped logger, or the default logger.
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
+
+// WithLogger stores l in ctx.
+func WithLogger(ctx context.Context, l *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// With returns a context whose logger carries the extra attributes.
+func With(ctx context.Context, args ...any) context.Context {
+	return WithLogger(ctx, FromContext(ctx).With(args...))
+}
+
+type requestIDKey struct{}
+
+// WithRequestID stores the correlation ID of the current request.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the correlation ID stored by WithRequestID, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
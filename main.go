@@ -1,76 +1,187 @@
 package main
 
 import (
+	"Go-PetStoreApp/apispec"
 	"Go-PetStoreApp/app"
 	"Go-PetStoreApp/controller"
-	"Go-PetStoreApp/exception"
 	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/logx"
+	"Go-PetStoreApp/metrics"
 	"Go-PetStoreApp/middleware"
+	"Go-PetStoreApp/migrations"
+	"Go-PetStoreApp/ratelimit"
 	"Go-PetStoreApp/repository"
+	"Go-PetStoreApp/search"
 	"Go-PetStoreApp/service"
+	"Go-PetStoreApp/tracing"
+	"context"
+	_ "embed"
+	"fmt"
+	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/go-playground/validator"
-	"github.com/julienschmidt/httprouter"
 )
 
+//go:embed apispec.yaml
+var apiSpec []byte
+
 func main() {
-	cfg := app.LoadConfig()
+	os.Exit(run(os.Args[1:]))
+}
+
+// run dispatches to a subcommand; with none (or "serve") it runs the API server.
+func run(args []string) int {
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	switch command {
+	case "serve":
+		cfg, code := loadConfig("serve", args)
+		if cfg == nil {
+			return code
+		}
+		serve(cfg)
+		return 0
+	case "config":
+		return configCommand(args)
+	case "users", "pets", "tokens", "deleted":
+		return runAdminCommand(command, args)
+	case "seed":
+		return seedCommand(args)
+	case "help":
+		printUsage(os.Stdout)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", command)
+		printUsage(os.Stderr)
+		return 2
+	}
+}
+
+func serve(cfg *app.Config) {
+	if err := logx.Setup(cfg.LogFormat, cfg.LogLevel); err != nil {
+		log.Fatalf("configuring logging: %v", err)
+	}
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		File:        cfg.TracingFile,
+		Endpoint:    cfg.TracingOTLPEndpoint,
+		Insecure:    cfg.TracingOTLPInsecure,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("configuring tracing: %v", err)
+	}
 	db := app.NewDB(cfg)
 	validate := validator.New()
+	jwt := helper.NewJWT(cfg.JWTSecretKey, cfg.TokenExpiry)
+	metrics.RegisterDB(db, cfg.DBName)
+
+	if cfg.AutoMigrate {
+		version, err := migrations.Apply(context.Background(), db)
+		if err != nil {
+			log.Fatalf("applying migrations: %v", err)
+		}
+		slog.Info("database schema ready", "version", version)
+	}
+
+	spec, err := apispec.Parse(apiSpec)
+	helper.PanicIfError(err)
+	specValidator := apispec.NewValidator(spec)
 
 	// Repositories
 	userRepo := repository.NewUserRepository()
 	petRepo := repository.NewPetRepository()
+	taxonomyRepo := repository.NewTaxonomyRepository()
+	photoRepo := repository.NewPetPhotoRepository()
+	auditRepo := repository.NewAuditRepository()
 
-	// Services (user needs token expiry)
-	userService := service.NewUserService(userRepo, db, validate, cfg.TokenExpiry)
-	petService := service.NewPetService(petRepo, db)
+	blobStore, mediaHandler, err := app.NewBlobStore(cfg)
+	if err != nil {
+		log.Fatalf("opening photo storage: %v", err)
+	}
+
+	// Services (user signs tokens)
+	var searchEngine search.Engine = search.NewPostgres()
+	if cfg.SearchBackend == "memory" {
+		searchEngine = search.NewMemory()
+	}
+	userService := service.NewUserService(userRepo, petRepo, auditRepo, db, validate, jwt, searchEngine)
+	petService := service.NewPetService(petRepo, userRepo, taxonomyRepo, photoRepo, auditRepo, db, validate, searchEngine, blobStore)
+	taxonomyService := service.NewTaxonomyService(taxonomyRepo, auditRepo, db, validate)
+	photoLimits := service.PhotoLimits{MaxBytes: int64(cfg.PhotoMaxBytes), MaxPixels: cfg.PhotoMaxPixels, MaxPerPet: cfg.PhotoMaxPerPet}
+	photoService := service.NewPetPhotoService(petRepo, photoRepo, auditRepo, blobStore, db, validate, photoLimits)
+	auditService := service.NewAuditService(auditRepo, petRepo, db, validate)
+	retentionService := service.NewRetentionService(userRepo, petRepo, photoRepo, auditRepo, blobStore, db)
+	if cfg.SearchBackend == "memory" {
+		indexed, err := petService.Reindex(context.Background())
+		if err != nil {
+			log.Fatalf("loading the search index: %v", err)
+		}
+		slog.Info("search index loaded", "pets", indexed)
+	}
 
 	// Controllers
 	userController := controller.NewUserController(userService)
-	petController := controller.NewPetController(petService)
+	petController := controller.NewPetController(petService, helper.NewCursors(cfg.CursorSecretKey, cfg.JWTSecretKey))
+	taxonomyController := controller.NewTaxonomyController(taxonomyService)
+	photoController := controller.NewPetPhotoController(photoService, photoLimits.MaxBytes)
+	auditController := controller.NewAuditController(auditService)
+	docsController := controller.NewDocsController(apiSpec, cfg.PublicURL)
+	healthController := controller.NewHealthController(db, jwt)
+	logController := controller.NewLogController()
 
 	// Middleware
-	jwtMiddleware := middleware.NewJWTMiddleware()
-	router := httprouter.New()
-
-	// --- User endpoints ---
-	router.POST("/api/users/register", userController.Register)
-	router.POST("/api/users/login", userController.Login)
-	router.POST("/api/auth/refresh", userController.RefreshToken)
-
-	router.GET("/api/users/:id", jwtMiddleware.Authenticate(userController.FindById))
-	router.PUT("/api/users/:id", jwtMiddleware.Authenticate(userController.Update))
-	router.PATCH("/api/users/:id/password", jwtMiddleware.Authenticate(userController.ChangePassword))
-	router.DELETE("/api/users/:id", jwtMiddleware.Authenticate(userController.Delete))
-	router.GET("/api/users", jwtMiddleware.Authenticate(userController.FindAll))
-
-	// Admin-only users
-	router.GET("/api/admin/users", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", userController.FindAll)))
-
-	// --- Pet endpoints ---
-	router.GET("/api/pets", jwtMiddleware.Authenticate(petController.FindAll))
-	router.POST("/api/pets", jwtMiddleware.Authenticate(petController.Create))
-	router.GET("/api/pets/:petId", jwtMiddleware.Authenticate(petController.FindById))
-	router.PUT("/api/pets/:petId", jwtMiddleware.Authenticate(petController.Update))
-	router.DELETE("/api/pets/:petId", jwtMiddleware.Authenticate(petController.Delete))
-
-	// Admin-only pets
-	router.GET("/api/admin/pets", jwtMiddleware.Authenticate(jwtMiddleware.RequireRole("admin", petController.FindAll)))
-
-	// Panic handler for JSON error response
-	router.PanicHandler = exception.ErrorHandler
-
-	// Wrap with logging middleware
-	httpHandler := middleware.CORS(middleware.LoggingMiddleware(router))
-
-	server := http.Server{
-		Addr:    "localhost:3000",
-		Handler: httpHandler,
+	jwtMiddleware := middleware.NewJWTMiddleware(jwt, userService)
+	var rateLimiter *middleware.RateLimiter
+	if cfg.RateLimitEnabled {
+		// already checked by cfg.Validate
+		policies, _ := ratelimit.ParsePolicies(cfg.RateLimitPolicies)
+		apiKeys, _ := ratelimit.ParseAPIKeys(cfg.RateLimitAPIKeys)
+		rateLimiter = middleware.NewRateLimiter(jwt, userService, ratelimit.NewMemoryStore(), policies, apiKeys, cfg.RateLimitTrustProxy)
 	}
+	router := app.NewRouter(userController, petController, taxonomyController, photoController, auditController, mediaHandler, docsController, healthController, logController, jwtMiddleware, rateLimiter)
 
-	if err := server.ListenAndServe(); err != nil {
-		helper.PanicIfError(err)
+	// Wrap with validation and logging middleware
+	var handler http.Handler = router
+	if cfg.APISpecValidation {
+		handler = specValidator.Middleware(handler, true)
+	}
+	handler = middleware.RequestValidation(specValidator, handler)
+	handler = middleware.MetricsMiddleware(handler)
+	corsConfig := middleware.CORSConfig{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   cfg.CORSAllowedMethods,
+		AllowedHeaders:   cfg.CORSAllowedHeaders,
+		ExposedHeaders:   cfg.CORSExposedHeaders,
+		MaxAge:           cfg.CORSMaxAge,
+		AllowCredentials: cfg.CORSAllowCredentials,
+	}
+	httpHandler := middleware.RequestID(middleware.Tracing(middleware.CORS(corsConfig, middleware.LoggingMiddleware(middleware.AuditSource(handler, cfg.RateLimitTrustProxy)))))
+
+	server := app.NewServer(cfg, httpHandler)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go app.RunPurge(ctx, cfg, retentionService)
+	if err := app.Serve(ctx, cfg, server, healthController.MarkShuttingDown); err != nil {
+		slog.Error("server stopped", "error", err)
+	}
+	if err := db.Close(); err != nil {
+		slog.Error("closing database", "error", err)
+	}
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("flushing traces", "error", err)
 	}
 }
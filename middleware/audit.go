@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"net/http"
+
+	"Go-PetStoreApp/audit"
+)
+
+// AuditSource puts an audit.Source with the client IP in the request context; Authenticate
+// adds the user, and services record both with the changes they make.
+func AuditSource(next http.Handler, trustProxy bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := audit.WithSource(r.Context(), clientIP(r, trustProxy))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -5,9 +5,14 @@ import (
 	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
+	"Go-PetStoreApp/audit"
 	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/logx"
+	"Go-PetStoreApp/tracing"
 	"github.com/julienschmidt/httprouter"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type contextKey string
@@ -18,37 +23,81 @@ const (
 	RoleKey   contextKey = "role"
 )
 
-type JWTMiddleware struct{}
+// TokenRevocations reports whether a user's token was revoked; service.UserService implements it.
+type TokenRevocations interface {
+	TokenRevoked(ctx context.Context, userID int, issuedAt time.Time) (bool, error)
+}
+
+type JWTMiddleware struct {
+	jwt         *helper.JWT
+	revocations TokenRevocations
+}
 
-func NewJWTMiddleware() *JWTMiddleware {
-	return &JWTMiddleware{}
+// NewJWTMiddleware validates tokens with jwt; revocations may be nil to skip the revocation check.
+func NewJWTMiddleware(jwt *helper.JWT, revocations TokenRevocations) *JWTMiddleware {
+	return &JWTMiddleware{jwt: jwt, revocations: revocations}
 }
 
 func (m *JWTMiddleware) Authenticate(next httprouter.Handle) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-		auth := r.Header.Get("Authorization")
-		if auth == "" {
-			writeError(w, "Authorization header required", http.StatusUnauthorized)
+		// the span covers the revocation lookup and the handler, whose spans are its children
+		ctx, span := tracing.Start(r.Context(), "JWTMiddleware.Authenticate")
+		defer span.End()
+		r = r.WithContext(ctx)
+		claims, msg := m.authenticate(r)
+		if claims == nil {
+			span.SetAttributes(attribute.String("auth.rejected", msg))
+			writeError(w, msg, http.StatusUnauthorized)
 			return
 		}
-		parts := strings.Fields(auth)
-		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			writeError(w, "invalid authorization header", http.StatusUnauthorized)
-			return
-		}
-		tokenStr := parts[1]
-		claims, err := helper.ValidateToken(tokenStr)
-		if err != nil {
-			writeError(w, "invalid or expired token", http.StatusUnauthorized)
-			return
-		}
-		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+		span.SetAttributes(attribute.Int("enduser.id", claims.UserID), attribute.String("enduser.role", claims.Role))
+
+		ctx = context.WithValue(ctx, UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, EmailKey, claims.Email)
 		ctx = context.WithValue(ctx, RoleKey, claims.Role)
+		ctx = logx.With(ctx, "user_id", claims.UserID, "role", claims.Role)
+		if info, ok := ctx.Value(requestInfoKey).(*requestInfo); ok {
+			info.userID, info.role = claims.UserID, claims.Role
+		}
+		audit.SetActor(ctx, claims.UserID)
 		next(w, r.WithContext(ctx), ps)
 	}
 }
 
+// authenticate validates the bearer token, returning the claims or the message for the 401.
+func (m *JWTMiddleware) authenticate(r *http.Request) (*helper.JWTClaims, string) {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return nil, "Authorization header required"
+	}
+	parts := strings.Fields(auth)
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return nil, "invalid authorization header"
+	}
+	claims, err := m.jwt.ValidateToken(parts[1])
+	if err != nil {
+		logx.FromContext(r.Context()).Info("rejected token", "error", err)
+		return nil, "invalid or expired token"
+	}
+	if m.revocations != nil {
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+		revoked, err := m.revocations.TokenRevoked(r.Context(), claims.UserID, issuedAt)
+		if err != nil {
+			// fail closed: a token we cannot check is not trusted
+			logx.FromContext(r.Context()).Error("checking token revocation", "error", err)
+			return nil, "invalid or expired token"
+		}
+		if revoked {
+			logx.FromContext(r.Context()).Info("rejected token", "error", "revoked")
+			return nil, "token revoked"
+		}
+	}
+	return claims, ""
+}
+
 // RequireRole returns a wrapper that requires a specific role (e.g., "admin")
 func (m *JWTMiddleware) RequireRole(role string, next httprouter.Handle) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
@@ -64,7 +113,11 @@ func (m *JWTMiddleware) RequireRole(role string, next httprouter.Handle) httprou
 func writeError(w http.ResponseWriter, msg string, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
+	body := map[string]string{"error": msg}
+	if id := w.Header().Get(helper.RequestIDHeader); id != "" {
+		body["request_id"] = id
+	}
+	_ = json.NewEncoder(w).Encode(body)
 }
 
 func GetUserIDFromContext(ctx context.Context) (int, bool) {
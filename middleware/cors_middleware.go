@@ -1,28 +1,116 @@
 package middleware
 
-import "net/http"
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
 
-// CORS adds Cross-Origin headers to allow frontend access
-func CORS(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Allow your frontend URL
-		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
+// CORSConfig is the cross-origin policy. Origins are exact ("https://app.example.com"),
+// wildcard-subdomain ("https://*.example.com", which does not match the apex) or "*".
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	MaxAge           time.Duration
+	AllowCredentials bool
+}
 
-		// Allow common headers
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+// CORS adds Cross-Origin headers for allowed origins. The matched origin is echoed back
+// (never "*"), so credentials work with wildcard patterns too. Preflights from other
+// origins, or asking for a method or header outside the policy, get 403.
+func CORS(cfg CORSConfig, next http.Handler) http.Handler {
+	methods := strings.Join(cfg.AllowedMethods, ", ")
+	headers := strings.Join(cfg.AllowedHeaders, ", ")
+	exposed := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
 
-		// Allow methods
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
 
-		// Allow cookies / credentials (optional)
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		allowed := originAllowed(cfg.AllowedOrigins, origin)
 
-		// Handle preflight request
-		if r.Method == http.MethodOptions {
+		if preflight {
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			if !allowed ||
+				!containsFold(cfg.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) ||
+				!headersAllowed(cfg.AllowedHeaders, r.Header.Get("Access-Control-Request-Headers")) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			h.Set("Access-Control-Allow-Origin", origin)
+			h.Set("Access-Control-Allow-Methods", methods)
+			if headers != "" {
+				h.Set("Access-Control-Allow-Headers", headers)
+			}
+			if cfg.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+			if cfg.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
+		// a disallowed origin still gets the response; without the headers the browser withholds it
+		if allowed {
+			h.Set("Access-Control-Allow-Origin", origin)
+			if exposed != "" {
+				h.Set("Access-Control-Expose-Headers", exposed)
+			}
+			if cfg.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
 		next.ServeHTTP(w, r)
 	})
 }
+
+func originAllowed(patterns []string, origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if p == "*" || p == origin {
+			return true
+		}
+		prefix, suffix, ok := strings.Cut(p, "*")
+		if !ok || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) || len(origin) <= len(prefix)+len(suffix) {
+			continue
+		}
+		// the wildcard covers subdomain labels only, not ports, paths or credentials
+		if sub := origin[len(prefix) : len(origin)-len(suffix)]; !strings.ContainsAny(sub, ":/@") {
+			return true
+		}
+	}
+	return false
+}
+
+// headersAllowed checks the comma-separated Access-Control-Request-Headers value.
+func headersAllowed(allowed []string, requested string) bool {
+	for _, h := range strings.Split(requested, ",") {
+		if h = strings.TrimSpace(h); h != "" && !containsFold(allowed, h) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if v == "*" || strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
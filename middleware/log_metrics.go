@@ -1,21 +1,18 @@
 package middleware
 
 import (
-	"encoding/json"
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
-)
 
-type Metrics struct {
-	mu            sync.RWMutex
-	RequestsTotal int64
-	ErrorsTotal   int64
-	PerPath       map[string]int64
-}
+	"Go-PetStoreApp/logx"
+	"Go-PetStoreApp/metrics"
+	"Go-PetStoreApp/tracing"
 
-var AppMetrics = &Metrics{PerPath: map[string]int64{}}
+	"github.com/julienschmidt/httprouter"
+)
 
 type loggingResponseWriter struct {
 	http.ResponseWriter
@@ -27,26 +24,101 @@ func (l *loggingResponseWriter) WriteHeader(code int) {
 	l.ResponseWriter.WriteHeader(code)
 }
 
+// requestInfo is filled in from inside the router (WithRoute, Authenticate) so middleware
+// running outside it can label by route template and log the authenticated user.
+type requestInfo struct {
+	pattern string
+	userID  int
+	role    string
+}
+
+const requestInfoKey contextKey = "request_info"
+
+// WithRoute records pattern (e.g. "/api/pets/:petId") as the matched route of the request.
+func WithRoute(pattern string, next httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		if info, ok := r.Context().Value(requestInfoKey).(*requestInfo); ok {
+			info.pattern = pattern
+		}
+		next(w, r.WithContext(logx.With(r.Context(), "route", pattern)), ps)
+	}
+}
+
+// GetRouteFromContext returns the matched route template, or "" before routing / when unmatched.
+func GetRouteFromContext(ctx context.Context) string {
+	if info, ok := ctx.Value(requestInfoKey).(*requestInfo); ok {
+		return info.pattern
+	}
+	return ""
+}
+
+func withRequestInfo(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(requestInfoKey).(*requestInfo); ok {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), requestInfoKey, &requestInfo{}))
+}
+
+// LoggingMiddleware attaches a request-scoped slog logger to the context and logs one
+// line per completed request. Handlers and services get it via logx.FromContext.
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		log.Printf("→ %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		r = withRequestInfo(r)
+		logger := slog.Default().With(
+			"request_id", logx.RequestID(r.Context()),
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		if traceID, spanID := tracing.IDs(r.Context()); traceID != "" {
+			logger = logger.With("trace_id", traceID, "span_id", spanID)
+		}
+		r = r.WithContext(logx.WithLogger(r.Context(), logger))
+		logger.Debug("request started", "remote_addr", r.RemoteAddr, "user_agent", r.UserAgent(), "headers", r.Header)
+
 		lrw := &loggingResponseWriter{ResponseWriter: w, status: 200}
 		next.ServeHTTP(lrw, r)
-		duration := time.Since(start)
-		log.Printf("← %s %s %d %s", r.Method, r.URL.Path, lrw.status, duration)
-		AppMetrics.mu.Lock()
-		AppMetrics.RequestsTotal++
-		AppMetrics.PerPath[r.URL.Path]++
-		if lrw.status >= 400 {
-			AppMetrics.ErrorsTotal++
+
+		// the handler chain enriched its own copy of the logger; pick the essentials up from requestInfo
+		attrs := []any{
+			"status", lrw.status,
+			"duration_ms", float64(time.Since(start).Microseconds()) / 1000,
+		}
+		if info, ok := r.Context().Value(requestInfoKey).(*requestInfo); ok {
+			if info.pattern != "" {
+				attrs = append(attrs, "route", info.pattern)
+			}
+			if info.userID != 0 {
+				attrs = append(attrs, "user_id", info.userID, "role", info.role)
+			}
 		}
-		AppMetrics.mu.Unlock()
+		level := slog.LevelInfo
+		if lrw.status >= 500 {
+			level = slog.LevelError
+		}
+		logger.Log(r.Context(), level, "request completed", attrs...)
 	})
 }
 
-func MetricsHandler(w http.ResponseWriter, r *http.Request) {
-	AppMetrics.mu.RLock()
-	defer AppMetrics.mu.RUnlock()
-	_ = json.NewEncoder(w).Encode(AppMetrics)
+// MetricsMiddleware records request counts and latencies labeled by route template,
+// method and status class. Requests the router did not match share the "unmatched" route
+// so arbitrary paths cannot grow the label set.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		r = withRequestInfo(r)
+		metrics.HTTPInFlight.Inc()
+		defer metrics.HTTPInFlight.Dec()
+
+		lrw := &loggingResponseWriter{ResponseWriter: w, status: 200}
+		next.ServeHTTP(lrw, r)
+
+		route := GetRouteFromContext(r.Context())
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(lrw.status/100) + "xx"
+		metrics.HTTPRequests.WithLabelValues(route, r.Method, status).Inc()
+		metrics.HTTPDuration.WithLabelValues(route, r.Method, status).Observe(time.Since(start).Seconds())
+	})
 }
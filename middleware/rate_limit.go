@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/logx"
+	"Go-PetStoreApp/metrics"
+	"Go-PetStoreApp/model/web"
+	"Go-PetStoreApp/ratelimit"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// APIKeyHeader carries the key of an API client; see ratelimit.APIKeys.
+const APIKeyHeader = "X-API-Key"
+
+// RateLimiter applies per-route token buckets. Callers with a valid bearer token are limited
+// per user, API clients with a known key per key, everybody else per client IP; admins are
+// exempt.
+type RateLimiter struct {
+	jwt         *helper.JWT
+	revocations TokenRevocations
+	store       ratelimit.Store
+	policies ratelimit.Policies
+	apiKeys  ratelimit.APIKeys
+	// trustProxy takes the client IP from the first X-Forwarded-For entry (behind a load balancer).
+	trustProxy bool
+}
+
+// NewRateLimiter limits requests by policies; apiKeys may be nil when there are no API clients,
+// and revocations nil to skip the revocation check as NewJWTMiddleware does.
+func NewRateLimiter(jwt *helper.JWT, revocations TokenRevocations, store ratelimit.Store, policies ratelimit.Policies, apiKeys ratelimit.APIKeys, trustProxy bool) *RateLimiter {
+	return &RateLimiter{jwt: jwt, revocations: revocations, store: store, policies: policies, apiKeys: apiKeys, trustProxy: trustProxy}
+}
+
+// Limit wraps the handle registered for method and route template pattern. Routes without a
+// matching policy are not limited. A failing store lets requests through.
+func (l *RateLimiter) Limit(method, pattern string, next httprouter.Handle) httprouter.Handle {
+	if l == nil {
+		return next
+	}
+	policy, ok := l.policies.Match(method, pattern)
+	if !ok {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		identity, exempt := l.identify(r)
+		if exempt {
+			next(w, r, ps)
+			return
+		}
+		res, err := l.store.Take(r.Context(), policy.Name+"|"+identity, policy)
+		if err != nil {
+			logx.FromContext(r.Context()).Error("rate limit store failed", "error", err)
+			next(w, r, ps)
+			return
+		}
+
+		h := w.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+		h.Set("RateLimit-Policy", strconv.Itoa(policy.Limit)+";w="+strconv.Itoa(ceilSeconds(policy.Period)))
+		if !res.Allowed {
+			h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			metrics.RateLimited.WithLabelValues(pattern).Inc()
+			logx.FromContext(r.Context()).Info("rate limited", "key", identity, "policy", policy.Name)
+			helper.WriteToResponseBody(w, web.WebResponse{
+				Code:   http.StatusTooManyRequests,
+				Status: http.StatusText(http.StatusTooManyRequests),
+				Data:   map[string]string{"error": "rate limit exceeded"},
+			})
+			return
+		}
+		next(w, r, ps)
+	}
+}
+
+// identify returns the bucket identity of the caller and whether it is exempt. Tokens are
+// checked here because the limiter runs before JWTMiddleware.Authenticate; an invalid or
+// revoked token counts as anonymous, and so does an unknown API key. The role in a token is
+// the one the user had when it was issued; SetRole revokes the user's older tokens, so only
+// the revocation check keeps a demoted admin's token from staying exempt.
+func (l *RateLimiter) identify(r *http.Request) (string, bool) {
+	if fields := strings.Fields(r.Header.Get("Authorization")); len(fields) == 2 && strings.EqualFold(fields[0], "bearer") {
+		if claims, err := l.jwt.ValidateToken(fields[1]); err == nil && !l.revoked(r, claims) {
+			return "user:" + strconv.Itoa(claims.UserID), claims.Role == "admin"
+		}
+	}
+	if name, ok := l.apiKeys.Lookup(r.Header.Get(APIKeyHeader)); ok {
+		return "key:" + name, false
+	}
+	return "ip:" + clientIP(r, l.trustProxy), false
+}
+
+// revoked reports whether the token was revoked; a failed lookup counts as revoked, as in
+// JWTMiddleware.authenticate.
+func (l *RateLimiter) revoked(r *http.Request, claims *helper.JWTClaims) bool {
+	if l.revocations == nil {
+		return false
+	}
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := l.revocations.TokenRevoked(r.Context(), claims.UserID, issuedAt)
+	if err != nil {
+		logx.FromContext(r.Context()).Error("checking token revocation", "error", err)
+		return true
+	}
+	return revoked
+}
+
+// clientIP returns the address of the client; with trustProxy, the first X-Forwarded-For entry.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			first, _, _ := strings.Cut(xff, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
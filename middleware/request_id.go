@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/logx"
+)
+
+// maxRequestIDLength bounds client-supplied IDs so they stay usable in logs and application_name.
+const maxRequestIDLength = 64
+
+// RequestID accepts a well-formed X-Request-ID from the caller (e.g. a gateway) or generates
+// one, stores it in the context and echoes it on the response so support can find the logs.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(helper.RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(helper.RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logx.WithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID allows letters, digits, '-', '_' and '.' so IDs can't inject into logs or SQL settings.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
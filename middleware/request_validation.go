@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"Go-PetStoreApp/apispec"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/model/web"
+)
+
+// maxValidatedBody bounds how much of a request body is buffered for validation.
+const maxValidatedBody = 1 << 20
+
+// RequestValidation rejects requests whose path parameters, query parameters or JSON body
+// do not match the operation declared in the OpenAPI spec. Routes the spec does not
+// describe are passed through untouched so the router can answer 404/405, and multipart
+// bodies are streamed to the handler after only the parameters are checked.
+func RequestValidation(v *apispec.Validator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, op, _, _, ok := v.Match(r.Method, r.URL.Path)
+		if !ok || op == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if apispec.StreamedBody(r) {
+			if errs := v.ValidateRequest(r, nil); len(errs) > 0 {
+				writeValidationError(w, http.StatusBadRequest, "validation failed", errs)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxValidatedBody+1))
+		if err != nil {
+			writeValidationError(w, http.StatusBadRequest, "could not read request body", nil)
+			return
+		}
+		if len(body) > maxValidatedBody {
+			writeValidationError(w, http.StatusRequestEntityTooLarge, "request body too large", nil)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if errs := v.ValidateRequest(r, body); len(errs) > 0 {
+			writeValidationError(w, http.StatusBadRequest, "validation failed", errs)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeValidationError(w http.ResponseWriter, status int, msg string, fields []apispec.FieldError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	data := map[string]interface{}{"error": msg}
+	if len(fields) > 0 {
+		data["fields"] = fields
+	}
+	_ = json.NewEncoder(w).Encode(web.WebResponse{
+		Code:      status,
+		Status:    http.StatusText(status),
+		Data:      data,
+		RequestID: w.Header().Get(helper.RequestIDHeader),
+	})
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"Go-PetStoreApp/logx"
+)
+
+// Tracing starts the server span of a request, continuing the caller's trace when a W3C
+// traceparent header is present. The span is renamed to "METHOD /route/:template" once the
+// router has matched, and the response carries a traceparent for the caller to correlate.
+func Tracing(next http.Handler) http.Handler {
+	tracer := otel.Tracer("Go-PetStoreApp/middleware")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		propagator := otel.GetTextMapPropagator()
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+				semconv.UserAgentOriginal(r.UserAgent()),
+				attribute.String("request_id", logx.RequestID(ctx)),
+			),
+		)
+		defer span.End()
+		propagator.Inject(ctx, propagation.HeaderCarrier(w.Header()))
+
+		r = withRequestInfo(r.WithContext(ctx))
+		lrw := &loggingResponseWriter{ResponseWriter: w, status: 200}
+		next.ServeHTTP(lrw, r)
+
+		if info, ok := r.Context().Value(requestInfoKey).(*requestInfo); ok {
+			if info.pattern != "" {
+				span.SetName(r.Method + " " + info.pattern)
+				span.SetAttributes(semconv.HTTPRoute(info.pattern))
+			}
+			if info.userID != 0 {
+				span.SetAttributes(attribute.Int("enduser.id", info.userID), attribute.String("enduser.role", info.role))
+			}
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(lrw.status))
+		if lrw.status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(lrw.status))
+		}
+	})
+}
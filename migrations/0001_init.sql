@@ -62,11 +62,3 @@ CREATE TRIGGER trg_pets_updated
 BEFORE UPDATE ON pets
 FOR EACH ROW
 EXECUTE FUNCTION set_updated_at();
-
-select * from users;
-
-select * from pets;
-
-drop table pets;
-
-drop table users;
\ No newline at end of file
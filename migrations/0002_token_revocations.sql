@@ -0,0 +1,8 @@
+-- ===============================
+-- TOKEN REVOCATIONS
+-- ===============================
+-- Access tokens of a user issued at or before revoked_before are rejected.
+CREATE TABLE IF NOT EXISTS token_revocations (
+    user_id INT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    revoked_before TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,12 @@
+-- ===============================
+-- PET LISTING INDEXES
+-- ===============================
+-- Listings are paged by id (keyset), so the filter indexes carry id to serve
+-- "WHERE created_by = $1 AND id < $2 ORDER BY id DESC" without a sort.
+CREATE INDEX IF NOT EXISTS idx_pets_owner_id ON pets (created_by, id);
+
+CREATE INDEX IF NOT EXISTS idx_pets_species_id ON pets (species, id);
+
+DROP INDEX IF EXISTS idx_pets_owner;
+
+DROP INDEX IF EXISTS idx_pets_species;
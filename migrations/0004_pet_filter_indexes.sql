@@ -0,0 +1,16 @@
+-- ===============================
+-- PET FILTER AND SORT INDEXES
+-- ===============================
+-- Sortable fields carry id, the keyset tie-breaker, so a sorted page is an index range scan.
+CREATE INDEX IF NOT EXISTS idx_pets_price_id ON pets (price, id);
+
+CREATE INDEX IF NOT EXISTS idx_pets_name_id ON pets (name, id);
+
+CREATE INDEX IF NOT EXISTS idx_pets_created_at_id ON pets (created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_pets_updated_at_id ON pets (updated_at, id);
+
+-- Trigram index for the case-insensitive substring filter (name ILIKE '%...%').
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_pets_name_trgm ON pets USING gin (name gin_trgm_ops);
@@ -0,0 +1,38 @@
+-- ===============================
+-- PET FULL-TEXT SEARCH
+-- ===============================
+-- search_vector is maintained by a trigger rather than a generated column so that later
+-- columns (and joined tables) can be added to the document by replacing the function.
+ALTER TABLE pets ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION pets_search_vector(p pets)
+RETURNS tsvector AS $$
+BEGIN
+    RETURN setweight(to_tsvector('english', coalesce(p.name, '')), 'A')
+        || setweight(to_tsvector('english', coalesce(p.species, '')), 'B');
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+CREATE OR REPLACE FUNCTION set_pets_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector = pets_search_vector(NEW);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_pets_search_vector ON pets;
+
+CREATE TRIGGER trg_pets_search_vector
+BEFORE INSERT OR UPDATE ON pets
+FOR EACH ROW
+EXECUTE FUNCTION set_pets_search_vector();
+
+-- Backfill without touching updated_at.
+ALTER TABLE pets DISABLE TRIGGER trg_pets_updated;
+
+UPDATE pets SET search_vector = pets_search_vector(pets);
+
+ALTER TABLE pets ENABLE TRIGGER trg_pets_updated;
+
+CREATE INDEX IF NOT EXISTS idx_pets_search_vector ON pets USING gin (search_vector);
@@ -0,0 +1,105 @@
+-- ===============================
+-- SPECIES AND BREEDS CATALOG
+-- ===============================
+-- Pets keep their species (and now breed) by name, but the names must exist in the
+-- catalog. Foreign keys cascade renames, and lower(name) indexes keep names unique
+-- regardless of case.
+CREATE TABLE IF NOT EXISTS species (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) UNIQUE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_species_name_lower ON species (lower(name));
+
+CREATE TABLE IF NOT EXISTS breeds (
+    id SERIAL PRIMARY KEY,
+    species VARCHAR(100) NOT NULL REFERENCES species (name) ON UPDATE CASCADE ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (species, name)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_breeds_species_name_lower ON breeds (species, lower(name));
+
+DROP TRIGGER IF EXISTS trg_species_updated ON species;
+
+CREATE TRIGGER trg_species_updated
+BEFORE UPDATE ON species
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+DROP TRIGGER IF EXISTS trg_breeds_updated ON breeds;
+
+CREATE TRIGGER trg_breeds_updated
+BEFORE UPDATE ON breeds
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- Starting catalog.
+INSERT INTO species (name) VALUES
+    ('dog'), ('cat'), ('bird'), ('fish'), ('rabbit'), ('hamster'), ('guinea pig'),
+    ('ferret'), ('parrot'), ('canary'), ('goldfish'), ('koi'), ('turtle'), ('snake'),
+    ('lizard'), ('hedgehog'), ('horse')
+ON CONFLICT DO NOTHING;
+
+-- ===============================
+-- NORMALIZE EXISTING PETS
+-- ===============================
+-- Free-text values are trimmed and lower-cased, common nicknames and plurals are mapped
+-- to their species, and anything left over becomes a species of its own.
+CREATE TEMPORARY TABLE species_aliases (alias TEXT PRIMARY KEY, name TEXT NOT NULL) ON COMMIT DROP;
+
+INSERT INTO species_aliases (alias, name) VALUES
+    ('dogs', 'dog'), ('doggo', 'dog'), ('doggy', 'dog'), ('doggie', 'dog'), ('puppy', 'dog'), ('pup', 'dog'),
+    ('cats', 'cat'), ('kitty', 'cat'), ('kitten', 'cat'), ('kitteh', 'cat'),
+    ('birds', 'bird'), ('birb', 'bird'),
+    ('bunny', 'rabbit'), ('rabbits', 'rabbit'),
+    ('hamsters', 'hamster'), ('guinea pigs', 'guinea pig'), ('guineapig', 'guinea pig'),
+    ('turtles', 'turtle'), ('tortoise', 'turtle'), ('snakes', 'snake'), ('lizards', 'lizard'),
+    ('horses', 'horse'), ('pony', 'horse'), ('fishes', 'fish');
+
+ALTER TABLE pets DISABLE TRIGGER trg_pets_updated;
+
+UPDATE pets
+SET species = regexp_replace(lower(trim(species)), '\s+', ' ', 'g')
+WHERE species <> regexp_replace(lower(trim(species)), '\s+', ' ', 'g');
+
+UPDATE pets p
+SET species = a.name
+FROM species_aliases a
+WHERE p.species = a.alias;
+
+UPDATE pets SET species = 'unknown' WHERE species = '';
+
+ALTER TABLE pets ENABLE TRIGGER trg_pets_updated;
+
+INSERT INTO species (name)
+SELECT DISTINCT species FROM pets
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE pets
+    ADD CONSTRAINT fk_pets_species FOREIGN KEY (species) REFERENCES species (name) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+-- ===============================
+-- PET BREED
+-- ===============================
+ALTER TABLE pets ADD COLUMN IF NOT EXISTS breed VARCHAR(100);
+
+-- The breed must belong to the pet's species; renames of either cascade.
+ALTER TABLE pets
+    ADD CONSTRAINT fk_pets_breed FOREIGN KEY (species, breed) REFERENCES breeds (species, name) ON UPDATE CASCADE ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_pets_breed ON pets (species, breed);
+
+-- Search the breed too.
+CREATE OR REPLACE FUNCTION pets_search_vector(p pets)
+RETURNS tsvector AS $$
+BEGIN
+    RETURN setweight(to_tsvector('english', coalesce(p.name, '')), 'A')
+        || setweight(to_tsvector('english', coalesce(p.species, '')), 'B')
+        || setweight(to_tsvector('english', coalesce(p.breed, '')), 'B');
+END;
+$$ LANGUAGE plpgsql STABLE;
@@ -0,0 +1,47 @@
+-- ===============================
+-- PET PROFILE
+-- ===============================
+-- Optional attributes; NULL means unknown. Weight is kept in the unit it was given in,
+-- and weight_kg normalizes it for filtering.
+ALTER TABLE pets
+    ADD COLUMN IF NOT EXISTS date_of_birth DATE,
+    ADD COLUMN IF NOT EXISTS sex VARCHAR(6),
+    ADD COLUMN IF NOT EXISTS color VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS weight NUMERIC(10,3),
+    ADD COLUMN IF NOT EXISTS weight_unit VARCHAR(2),
+    ADD COLUMN IF NOT EXISTS neutered BOOLEAN,
+    ADD COLUMN IF NOT EXISTS microchip VARCHAR(15),
+    ADD COLUMN IF NOT EXISTS description TEXT;
+
+ALTER TABLE pets
+    ADD COLUMN IF NOT EXISTS weight_kg NUMERIC(16,6) GENERATED ALWAYS AS (
+        CASE weight_unit
+            WHEN 'g' THEN weight / 1000
+            WHEN 'lb' THEN weight * 0.45359237
+            WHEN 'oz' THEN weight * 0.028349523125
+            ELSE weight
+        END
+    ) STORED;
+
+ALTER TABLE pets
+    ADD CONSTRAINT chk_pets_sex CHECK (sex IN ('male', 'female')),
+    ADD CONSTRAINT chk_pets_weight CHECK (weight > 0 AND weight_unit IN ('kg', 'g', 'lb', 'oz') OR weight IS NULL AND weight_unit IS NULL);
+
+-- A microchip identifies one animal.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pets_microchip ON pets (microchip) WHERE microchip IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_pets_date_of_birth ON pets (date_of_birth);
+
+CREATE INDEX IF NOT EXISTS idx_pets_weight_kg ON pets (weight_kg);
+
+-- Search the color and description too, below the name, species and breed.
+CREATE OR REPLACE FUNCTION pets_search_vector(p pets)
+RETURNS tsvector AS $$
+BEGIN
+    RETURN setweight(to_tsvector('english', coalesce(p.name, '')), 'A')
+        || setweight(to_tsvector('english', coalesce(p.species, '')), 'B')
+        || setweight(to_tsvector('english', coalesce(p.breed, '')), 'B')
+        || setweight(to_tsvector('english', coalesce(p.color, '')), 'C')
+        || setweight(to_tsvector('english', coalesce(p.description, '')), 'D');
+END;
+$$ LANGUAGE plpgsql STABLE;
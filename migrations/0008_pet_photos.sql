@@ -0,0 +1,22 @@
+-- ===============================
+-- PET PHOTOS
+-- ===============================
+-- The image files live in blob storage; rows keep their keys. Positions are checked at
+-- the end of each statement so that a reorder can swap them in one UPDATE.
+CREATE TABLE IF NOT EXISTS pet_photos (
+    id SERIAL PRIMARY KEY,
+    pet_id INT NOT NULL REFERENCES pets(id) ON DELETE CASCADE,
+    position INT NOT NULL CHECK (position > 0),
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    content_type VARCHAR(50) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    size_bytes INT NOT NULL,
+    original_key VARCHAR(255) NOT NULL,
+    medium_key VARCHAR(255) NOT NULL,
+    thumbnail_key VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_pet_photos_position UNIQUE (pet_id, position) DEFERRABLE INITIALLY IMMEDIATE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pet_photos_primary ON pet_photos (pet_id) WHERE is_primary;
@@ -0,0 +1,28 @@
+-- ===============================
+-- SOFT DELETE
+-- ===============================
+-- Deleting a user or a pet sets deleted_at; the row stays restorable until the purge job
+-- hard-deletes it once the retention period is over. A user's pets are deleted with the
+-- same deleted_at, which is how restoring the user finds them again.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE pets ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- Deleting a user row no longer takes its pets along: hard deletes only come from the
+-- purge, which removes a user's pets (and their photo files) first, and a stray pet now
+-- fails the delete instead of vanishing unaudited with its files left behind.
+ALTER TABLE pets DROP CONSTRAINT IF EXISTS pets_created_by_fkey;
+ALTER TABLE pets ADD CONSTRAINT pets_created_by_fkey FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE RESTRICT;
+
+-- Usernames, email addresses and microchips are only reserved by live rows; restoring a
+-- row checks that its values are still free.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_users_username ON users (username) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_users_email ON users (email) WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS uq_pets_microchip;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_pets_microchip ON pets (microchip) WHERE microchip IS NOT NULL AND deleted_at IS NULL;
+
+-- For the admin listings and the purge, which only look at deleted rows.
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_pets_deleted_at ON pets (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,37 @@
+-- ===============================
+-- AUDIT LOG
+-- ===============================
+-- One row per change to a user or pet, written in the transaction that makes the change.
+-- There are no foreign keys: entries outlive the users and pets they are about.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    actor_id INT, -- NULL outside an authenticated request, e.g. admin commands
+    action VARCHAR(20) NOT NULL,
+    entity VARCHAR(20) NOT NULL,
+    entity_id INT NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id VARCHAR(64),
+    ip VARCHAR(45)
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log (entity, entity_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log (actor_id, id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_occurred_at ON audit_log (occurred_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_request ON audit_log (request_id) WHERE request_id IS NOT NULL;
+
+-- Append-only: entries cannot be changed or removed, not even by the application.
+CREATE OR REPLACE FUNCTION audit_log_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_log_append_only ON audit_log;
+
+CREATE TRIGGER trg_audit_log_append_only
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW
+EXECUTE FUNCTION audit_log_append_only();
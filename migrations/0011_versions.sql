@@ -0,0 +1,8 @@
+-- ===============================
+-- ROW VERSIONS
+-- ===============================
+-- Every change to a user or pet increments its version, which the API exposes as the ETag.
+-- Updates check the version they read in their WHERE clause, so a concurrent change makes
+-- them fail instead of being overwritten.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
+ALTER TABLE pets ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
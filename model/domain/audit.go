@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry records one change to a user or pet. Before and After hold the changed fields
+// with their old and new values; a create has no Before. ActorID is 0 and RequestID and IP
+// are empty for changes made outside an authenticated request.
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	ActorID    int             `json:"actor_id"`
+	Action     string          `json:"action"`
+	Entity     string          `json:"entity"`
+	EntityID   int             `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+	IP         string          `json:"ip"`
+}
@@ -6,8 +6,22 @@ type Pet struct {
 	ID        int     `json:"id"`
 	Name      string  `json:"name"`
 	Species   string  `json:"species"`
+	Breed     string  `json:"breed"` // "" when unknown
 	Price     float64 `json:"price"`
 	CreatedBy int     `json:"created_by"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
-}
\ No newline at end of file
+
+	// Optional profile; zero values (and a nil Neutered) mean unknown.
+	DateOfBirth time.Time `json:"date_of_birth"` // a date, at midnight UTC
+	Sex         string    `json:"sex"`           // "male" or "female"
+	Color       string    `json:"color"`
+	Weight      float64   `json:"weight"`      // in WeightUnit
+	WeightUnit  string    `json:"weight_unit"` // "kg", "g", "lb" or "oz"
+	Neutered    *bool     `json:"neutered"`
+	Microchip   string    `json:"microchip"` // unique across the store
+	Description string    `json:"description"`
+
+	DeletedAt time.Time `json:"deleted_at"` // zero unless soft-deleted
+	Version   int       `json:"version"`    // incremented by every change
+}
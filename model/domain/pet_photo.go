@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// PetPhoto is an uploaded picture of a pet, stored in three sizes under blob keys.
+// Positions order a pet's photos from 1; at most one of them is the primary photo.
+type PetPhoto struct {
+	ID           int       `json:"id"`
+	PetID        int       `json:"pet_id"`
+	Position     int       `json:"position"`
+	Primary      bool      `json:"primary"`
+	ContentType  string    `json:"content_type"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	SizeBytes    int       `json:"size_bytes"`
+	OriginalKey  string    `json:"original_key"`
+	MediumKey    string    `json:"medium_key"`
+	ThumbnailKey string    `json:"thumbnail_key"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+type Species struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Breed belongs to a species, referenced by name like pets do.
+type Breed struct {
+	ID        int       `json:"id"`
+	Species   string    `json:"species"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -10,4 +10,6 @@ type User struct {
 	Role string `json:"role"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+	DeletedAt    time.Time `json:"deleted_at"` // zero unless soft-deleted
+	Version      int       `json:"version"`    // incremented by every change
 }
\ No newline at end of file
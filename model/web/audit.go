@@ -0,0 +1,49 @@
+package web
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit listings return DefaultAuditPageLimit entries unless ?limit asks for up to MaxAuditPageLimit.
+const (
+	DefaultAuditPageLimit = 50
+	MaxAuditPageLimit     = 500
+)
+
+// AuditListRequest filters the audit log; zero fields match everything and the time range
+// is [From, Before).
+type AuditListRequest struct {
+	ActorID   int
+	Action    string `validate:"omitempty,oneof=create update delete restore purge"`
+	Entity    string `validate:"omitempty,oneof=pet user"`
+	EntityID  int
+	RequestID string
+	From      time.Time
+	Before    time.Time
+	Page      int `validate:"gte=1"`
+	Limit     int `validate:"gte=1,lte=500"`
+}
+
+// AuditEntryResponse is one change. Before and After hold only the fields that changed;
+// Before is null for a create. ActorID is omitted for changes made outside the API, and IP
+// is only shown to admins.
+type AuditEntryResponse struct {
+	Id         int64           `json:"id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	ActorId    int             `json:"actor_id,omitempty"`
+	Action     string          `json:"action"`
+	Entity     string          `json:"entity"`
+	EntityId   int             `json:"entity_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestId  string          `json:"request_id,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+}
+
+type AuditPageResponse struct {
+	Items []AuditEntryResponse `json:"items"`
+	Page  int                  `json:"page"`
+	Limit int                  `json:"limit"`
+	Total int                  `json:"total"`
+}
@@ -0,0 +1,11 @@
+package web
+
+// PatchRequest is a partial update of the pet or user Id: a JSON Merge Patch or a JSON Patch,
+// per ContentType, of the entity's update request document. IfMatch is the If-Match header,
+// as in PetUpdateRequest.
+type PatchRequest struct {
+	Id          int
+	IfMatch     string
+	ContentType string
+	Patch       []byte
+}
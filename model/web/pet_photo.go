@@ -0,0 +1,36 @@
+package web
+
+import "time"
+
+// PetPhotoResponse links to the stored sizes of a photo. Width and Height are those of the
+// original; the medium and thumbnail sizes fit within 1024 and 256 pixels.
+type PetPhotoResponse struct {
+	Id          int          `json:"id"`
+	Position    int          `json:"position"`
+	Primary     bool         `json:"primary"`
+	ContentType string       `json:"content_type"`
+	Width       int          `json:"width"`
+	Height      int          `json:"height"`
+	URLs        PetPhotoURLs `json:"urls"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type PetPhotoURLs struct {
+	Original  string `json:"original"`
+	Medium    string `json:"medium"`
+	Thumbnail string `json:"thumbnail"`
+}
+
+// PetPhotoUpload is an uploaded image file; Primary makes it the pet's primary photo, which
+// the first photo of a pet always is.
+type PetPhotoUpload struct {
+	PetId   int
+	Data    []byte
+	Primary bool
+}
+
+// PetPhotoOrderRequest lists all of a pet's photo IDs in their new order.
+type PetPhotoOrderRequest struct {
+	PetId    int   `json:"-"`
+	PhotoIds []int `json:"photo_ids" validate:"required,min=1,max=100,dive,gt=0"`
+}
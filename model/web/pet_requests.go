@@ -1,14 +1,92 @@
 package web
 
+import "time"
+
+// Species and Breed must name catalog entries; case does not matter.
 type PetCreateRequest struct {
 	Name    string  `json:"name" validate:"required"`
 	Species string  `json:"species" validate:"required"`
-	Price   float64 `json:"price" validate:"required,gte=0"`
+	Breed   string  `json:"breed,omitempty" validate:"max=100"`
+	Price   float64 `json:"price" validate:"gte=0"`
+	PetProfile
 }
 
+// IfMatch is the request's If-Match header; the update fails if it names another version.
 type PetUpdateRequest struct {
 	Id      int     `json:"id"`
+	IfMatch string  `json:"-"`
 	Name    string  `json:"name" validate:"required"`
 	Species string  `json:"species" validate:"required"`
-	Price   float64 `json:"price" validate:"required,gte=0"`
+	Breed   string  `json:"breed,omitempty" validate:"max=100"`
+	Price   float64 `json:"price" validate:"gte=0"`
+	PetProfile
+}
+
+// PetProfile holds the optional attributes of a pet; omitted fields are unknown, and an
+// update clears the ones it omits. WeightUnit defaults to kg. Microchip spaces and dashes
+// are ignored.
+type PetProfile struct {
+	DateOfBirth string  `json:"date_of_birth,omitempty"` // 2006-01-02, not in the future
+	Sex         string  `json:"sex,omitempty" validate:"omitempty,oneof=male female"`
+	Color       string  `json:"color,omitempty" validate:"max=50"`
+	Weight      float64 `json:"weight,omitempty" validate:"gte=0,lte=100000"`
+	WeightUnit  string  `json:"weight_unit,omitempty" validate:"omitempty,oneof=kg g lb oz"`
+	Neutered    *bool   `json:"neutered,omitempty"`
+	Microchip   string  `json:"microchip,omitempty" validate:"omitempty,alphanum,min=9,max=15"`
+	Description string  `json:"description,omitempty" validate:"max=2000"`
+}
+
+// Pet listings return DefaultPetPageLimit pets unless ?limit asks for up to MaxPetPageLimit.
+const (
+	DefaultPetPageLimit = 10
+	MaxPetPageLimit     = 100
+)
+
+// PetListRequest is a parsed pet listing query. A Cursor selects keyset pagination and
+// Page is then ignored; OwnerID 0 lists every owner's pets (admins only). Zero filters
+// match everything; time ranges are [From, Before).
+type PetListRequest struct {
+	OwnerID       int
+	Species       []string
+	Name          string
+	MinPrice      *float64
+	MaxPrice      *float64
+	CreatedFrom   time.Time
+	CreatedBefore time.Time
+	UpdatedFrom   time.Time
+	UpdatedBefore time.Time
+	Sex           string
+	Color         string // case-insensitive substring
+	Neutered      *bool
+	Microchip     string
+	BornFrom      time.Time
+	BornBefore    time.Time
+	MinAge        *int // whole years
+	MaxAge        *int
+	MinWeightKg   *float64
+	MaxWeightKg   *float64
+	Sort          string // e.g. "price,-created_at"
+	Deleted       bool   // list soft-deleted pets instead of live ones
+
+	Page      int
+	Limit     int
+	Cursor    *PetCursor
+	SkipTotal bool
+}
+
+// PetCursor is the position a listing continues from: the sort key of the last pet seen,
+// or with Before, of the first pet of the page before which the client wants the
+// previous page. Sort is the listing order it was issued for.
+type PetCursor struct {
+	Sort   string   `json:"s,omitempty"`
+	Key    []string `json:"k"`
+	Before bool     `json:"b,omitempty"`
+}
+
+// PetSearchRequest is a free-text search; see GET /api/pets/search.
+type PetSearchRequest struct {
+	Query   string `validate:"required,max=200"`
+	OwnerID int
+	Page    int `validate:"gte=1"`
+	Limit   int `validate:"gte=1,lte=100"`
 }
@@ -6,8 +6,63 @@ type PetResponse struct {
 	Id        int       `json:"id"`
 	Name      string    `json:"name"`
 	Species   string    `json:"species"`
+	Breed     string    `json:"breed,omitempty"`
 	Price     float64   `json:"price"`
 	OwnerId   int       `json:"owner_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	DateOfBirth string  `json:"date_of_birth,omitempty"`
+	Age         *PetAge `json:"age,omitempty"` // computed from DateOfBirth
+	Sex         string  `json:"sex,omitempty"`
+	Color       string  `json:"color,omitempty"`
+	Weight      float64 `json:"weight,omitempty"`
+	WeightUnit  string  `json:"weight_unit,omitempty"`
+	Neutered    *bool   `json:"neutered,omitempty"`
+	Microchip   string  `json:"microchip,omitempty"`
+	Description string  `json:"description,omitempty"`
+
+	Photos []PetPhotoResponse `json:"photos,omitempty"` // in display order
+
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // deleted pets only, in admin listings
+	Version   int        `json:"version"`              // also sent as the ETag
+}
+
+// PetAge is the completed years and months since a pet's date of birth.
+type PetAge struct {
+	Years  int `json:"years"`
+	Months int `json:"months"`
+}
+
+type PetPageResponse struct {
+	Items []PetResponse `json:"items"`
+	Page  int           `json:"page,omitempty"` // offset pagination only
+	Limit int           `json:"limit"`
+	Total *int          `json:"total,omitempty"` // omitted with ?total=false
+
+	// NextCursor and PrevCursor are opaque; pass them back as ?cursor=.
+	NextCursor string    `json:"next_cursor,omitempty"`
+	PrevCursor string    `json:"prev_cursor,omitempty"`
+	Links      PageLinks `json:"links"`
+}
+
+// PageLinks are ready-made URLs of the neighbouring pages, with the same filters.
+type PageLinks struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// PetSearchHit is a search result. Highlights maps each searched field to its HTML-escaped
+// text with the matching words in <mark></mark>.
+type PetSearchHit struct {
+	PetResponse
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+type PetSearchResponse struct {
+	Items []PetSearchHit `json:"items"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+	Total int            `json:"total"`
 }
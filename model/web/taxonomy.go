@@ -0,0 +1,26 @@
+package web
+
+import "time"
+
+type SpeciesRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type BreedRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type SpeciesResponse struct {
+	Id        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type BreedResponse struct {
+	Id        int       `json:"id"`
+	Species   string    `json:"species"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -1,10 +1,12 @@
 package web
 
+// Role is not read from the request body, so public registration always creates a user;
+// admin commands set it to create administrators.
 type UserRegisterRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
-	Role     string `json:"role,omitempty"`
+	Role     string `json:"-" validate:"omitempty,oneof=user admin"`
 }
 
 type UserLoginRequest struct {
@@ -12,14 +14,25 @@ type UserLoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// IfMatch is the request's If-Match header, as in PetUpdateRequest.
 type UserUpdateRequest struct {
 	Id       int    `json:"id"`
+	IfMatch  string `json:"-"`
 	Username string `json:"username" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
 }
 
 type UserChangePasswordRequest struct {
 	Id          int    `json:"-"`
+	IfMatch     string `json:"-"`
 	OldPassword string `json:"old_password" validate:"required"`
 	NewPassword string `json:"new_password" validate:"required,min=6"`
 }
+
+type UserSetRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user admin"`
+}
+
+type UserResetPasswordRequest struct {
+	NewPassword string `json:"new_password" validate:"required,min=6"`
+}
@@ -4,4 +4,7 @@ type WebResponse struct {
 	Code   int         `json:"code"`
 	Status string      `json:"status"`
 	Data   interface{} `json:"data,omitempty"`
+
+	// RequestID is set on error responses so users can quote it when reporting a failure.
+	RequestID string `json:"request_id,omitempty"`
 }
@@ -0,0 +1,206 @@
+// Package ratelimit implements token-bucket rate limiting. A Store keeps the buckets;
+// MemoryStore serves a single instance, and a shared implementation (e.g. Redis) can be
+// plugged in for several replicas.
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Policy allows Limit requests per Period, refilled continuously, with bursts of up to Limit.
+type Policy struct {
+	Name   string // the pattern the policy was configured for, e.g. "POST /api/users/login"
+	Limit  int
+	Period time.Duration
+}
+
+func (p Policy) refillInterval() time.Duration {
+	return p.Period / time.Duration(p.Limit)
+}
+
+// Result describes the bucket after a Take.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the next request would be allowed; zero when Allowed
+}
+
+// Store takes one token from the bucket identified by key.
+type Store interface {
+	Take(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	fullAt  time.Time
+}
+
+// MemoryStore keeps buckets in process memory; idle buckets are dropped once full.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// sweepInterval bounds how often Take scans for idle buckets.
+const sweepInterval = time.Minute
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, policy Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	capacity := float64(policy.Limit)
+	perToken := policy.refillInterval()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+float64(now.Sub(b.updated))/float64(perToken))
+	b.updated = now
+
+	res := Result{Limit: policy.Limit}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) * float64(perToken))
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration((capacity - b.tokens) * float64(perToken))
+	b.fullAt = now.Add(res.Reset)
+
+	if now.Sub(s.lastSweep) > sweepInterval {
+		s.sweep(now)
+	}
+	return res, nil
+}
+
+// sweep drops buckets that have refilled completely, so they cost nothing to recreate.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.After(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// Policies maps route patterns to policies. Keys are "METHOD /path", "/path" (any method)
+// or a prefix ending in "*" such as "/api/*"; Match prefers them in that order.
+type Policies map[string]Policy
+
+// ParsePolicies reads a comma-separated list of pattern=limit/period entries, e.g.
+// "POST /api/users/login=10/1m, /api/*=300/1m". A bare unit ("10/m") means one of it.
+func ParsePolicies(s string) (Policies, error) {
+	policies := Policies{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		pattern, rate, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("ratelimit: %q: want pattern=limit/period", entry)
+		}
+		pattern = strings.Join(strings.Fields(pattern), " ")
+		limitStr, periodStr, ok := strings.Cut(strings.TrimSpace(rate), "/")
+		if !ok {
+			return nil, fmt.Errorf("ratelimit: %q: want limit/period", entry)
+		}
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("ratelimit: %q: limit must be a positive integer", entry)
+		}
+		if periodStr != "" && (periodStr[0] < '0' || periodStr[0] > '9') {
+			periodStr = "1" + periodStr
+		}
+		period, err := time.ParseDuration(periodStr)
+		if err != nil || period <= 0 {
+			return nil, fmt.Errorf("ratelimit: %q: bad period", entry)
+		}
+		policies[pattern] = Policy{Name: pattern, Limit: limit, Period: period}
+	}
+	return policies, nil
+}
+
+// APIKeys maps the SHA-256 of each API key to the name of its client, so callers that
+// present a key get a bucket of their own rather than sharing their IP's.
+type APIKeys map[[sha256.Size]byte]string
+
+// minAPIKeyLength keeps keys long enough not to be guessed.
+const minAPIKeyLength = 16
+
+// ParseAPIKeys reads a comma-separated list of name=key entries, e.g. "billing=3f9c...".
+func ParseAPIKeys(s string) (APIKeys, error) {
+	keys := APIKeys{}
+	names := map[string]bool{}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, key, ok := strings.Cut(entry, "=")
+		name, key = strings.TrimSpace(name), strings.TrimSpace(key)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("ratelimit: API key entries must be name=key")
+		}
+		if len(key) < minAPIKeyLength {
+			return nil, fmt.Errorf("ratelimit: API key %q must be at least %d characters", name, minAPIKeyLength)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("ratelimit: API key %q is listed twice", name)
+		}
+		names[name] = true
+		keys[sha256.Sum256([]byte(key))] = name
+	}
+	return keys, nil
+}
+
+// Lookup returns the client name of key.
+func (k APIKeys) Lookup(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	name, ok := k[sha256.Sum256([]byte(key))]
+	return name, ok
+}
+
+// Match returns the policy for a request to the route template path.
+func (p Policies) Match(method, path string) (Policy, bool) {
+	if policy, ok := p[method+" "+path]; ok {
+		return policy, true
+	}
+	if policy, ok := p[path]; ok {
+		return policy, true
+	}
+	var prefixes []string
+	for key := range p {
+		if strings.HasSuffix(key, "*") && strings.HasPrefix(path, strings.TrimSuffix(key, "*")) {
+			prefixes = append(prefixes, key)
+		}
+	}
+	if len(prefixes) == 0 {
+		return Policy{}, false
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return len(prefixes[i]) > len(prefixes[j]) })
+	return p[prefixes[0]], true
+}
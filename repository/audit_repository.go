@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"Go-PetStoreApp/model/domain"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// AuditQuery filters the audit log. Zero fields match everything; the time range is
+// [From, Before).
+type AuditQuery struct {
+	ActorID   int
+	Action    string
+	Entity    string
+	EntityID  int
+	RequestID string
+	From      time.Time
+	Before    time.Time
+	Limit     int
+	Offset    int
+}
+
+// AuditRepository appends to the audit log; there is deliberately no way to change it.
+type AuditRepository interface {
+	Create(ctx context.Context, tx *sql.Tx, entry domain.AuditEntry)
+	// FindPage returns a page of matching entries, newest first, and how many match in total.
+	FindPage(ctx context.Context, tx *sql.Tx, q AuditQuery) ([]domain.AuditEntry, int)
+}
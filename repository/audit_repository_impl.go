@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/tracing"
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+type AuditRepositoryImpl struct{}
+
+func NewAuditRepository() AuditRepository {
+	return &AuditRepositoryImpl{}
+}
+
+func (r *AuditRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, entry domain.AuditEntry) {
+	actor := sql.NullInt64{Int64: int64(entry.ActorID), Valid: entry.ActorID != 0}
+	sql := `INSERT INTO audit_log (occurred_at, actor_id, action, entity, entity_id, before, after, request_id, ip)
+	        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	ctx, span := tracing.StartQuery(ctx, "AuditRepository.Create", sql)
+	defer span.End()
+	_, err := tx.ExecContext(ctx, sql, entry.OccurredAt, actor, entry.Action, entry.Entity, entry.EntityID,
+		nullJSON(entry.Before), nullJSON(entry.After), nullIfEmpty(entry.RequestID), nullIfEmpty(entry.IP))
+	helper.PanicIfError(err)
+}
+
+func (r *AuditRepositoryImpl) FindPage(ctx context.Context, tx *sql.Tx, q AuditQuery) ([]domain.AuditEntry, int) {
+	var conds []string
+	var args []any
+	add := func(cond string, value any) {
+		args = append(args, value)
+		conds = append(conds, strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))))
+	}
+	if q.ActorID != 0 {
+		add("actor_id = ?", q.ActorID)
+	}
+	if q.Action != "" {
+		add("action = ?", q.Action)
+	}
+	if q.Entity != "" {
+		add("entity = ?", q.Entity)
+	}
+	if q.EntityID != 0 {
+		add("entity_id = ?", q.EntityID)
+	}
+	if q.RequestID != "" {
+		add("request_id = ?", q.RequestID)
+	}
+	if !q.From.IsZero() {
+		add("occurred_at >= ?", q.From)
+	}
+	if !q.Before.IsZero() {
+		add("occurred_at < ?", q.Before)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	countSQL := "SELECT COUNT(*) FROM audit_log" + where
+	countCtx, countSpan := tracing.StartQuery(ctx, "AuditRepository.FindPage.count", countSQL)
+	var total int
+	err := tx.QueryRowContext(countCtx, countSQL, args...).Scan(&total)
+	countSpan.End()
+	helper.PanicIfError(err)
+
+	n := len(args)
+	dataSQL := `SELECT id, occurred_at, actor_id, action, entity, entity_id, before, after, request_id, ip
+	        FROM audit_log` + where + ` ORDER BY id DESC LIMIT $` + strconv.Itoa(n+1) + ` OFFSET $` + strconv.Itoa(n+2)
+	ctx, span := tracing.StartQuery(ctx, "AuditRepository.FindPage", dataSQL)
+	defer span.End()
+	rows, err := tx.QueryContext(ctx, dataSQL, append(args, q.Limit, q.Offset)...)
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	var entries []domain.AuditEntry
+	for rows.Next() {
+		var e domain.AuditEntry
+		var actor sql.NullInt64
+		var before, after []byte
+		var requestID, ip sql.NullString
+		err := rows.Scan(&e.ID, &e.OccurredAt, &actor, &e.Action, &e.Entity, &e.EntityID, &before, &after, &requestID, &ip)
+		helper.PanicIfError(err)
+		e.ActorID, e.Before, e.After, e.RequestID, e.IP = int(actor.Int64), before, after, requestID.String, ip.String
+		entries = append(entries, e)
+	}
+	helper.PanicIfError(rows.Err())
+	return entries, total
+}
+
+// nullJSON stores an absent snapshot as NULL rather than JSON null.
+func nullJSON(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"Go-PetStoreApp/model/domain"
+	"context"
+	"database/sql"
+)
+
+// PetPhotoRepository stores photo metadata; the images themselves are in blob storage.
+// Lists are in position order.
+type PetPhotoRepository interface {
+	// Lock serializes changes to a pet's photos until tx ends by locking the pet's row.
+	Lock(ctx context.Context, tx *sql.Tx, petID int)
+	Create(ctx context.Context, tx *sql.Tx, photo domain.PetPhoto) domain.PetPhoto
+	FindById(ctx context.Context, tx *sql.Tx, petID, id int) (domain.PetPhoto, error)
+	FindByPet(ctx context.Context, tx *sql.Tx, petID int) []domain.PetPhoto
+	// FindByPets loads the photos of several pets at once, for listings.
+	FindByPets(ctx context.Context, tx *sql.Tx, petIDs []int) map[int][]domain.PetPhoto
+	// FindAll returns every photo, of deleted pets too, e.g. to delete their files on a reset.
+	FindAll(ctx context.Context, tx *sql.Tx) []domain.PetPhoto
+	// SetPrimary makes photoID the only primary photo of the pet.
+	SetPrimary(ctx context.Context, tx *sql.Tx, petID, photoID int)
+	// SetOrder numbers the given photos 1, 2, ... in order; it must list all of the pet's photos.
+	SetOrder(ctx context.Context, tx *sql.Tx, petID int, photoIDs []int)
+	// Delete removes a photo and closes the gap it leaves in the positions.
+	Delete(ctx context.Context, tx *sql.Tx, photo domain.PetPhoto)
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/tracing"
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+type PetPhotoRepositoryImpl struct{}
+
+const petPhotoColumns = `id, pet_id, position, is_primary, content_type, width, height, size_bytes,
+	original_key, medium_key, thumbnail_key, created_at`
+
+func scanPetPhoto(row interface{ Scan(dest ...any) error }) (domain.PetPhoto, error) {
+	var p domain.PetPhoto
+	err := row.Scan(&p.ID, &p.PetID, &p.Position, &p.Primary, &p.ContentType, &p.Width, &p.Height, &p.SizeBytes,
+		&p.OriginalKey, &p.MediumKey, &p.ThumbnailKey, &p.CreatedAt)
+	return p, err
+}
+
+func NewPetPhotoRepository() PetPhotoRepository {
+	return &PetPhotoRepositoryImpl{}
+}
+
+func (r *PetPhotoRepositoryImpl) Lock(ctx context.Context, tx *sql.Tx, petID int) {
+	sql := `SELECT id FROM pets WHERE id = $1 FOR UPDATE`
+	ctx, span := tracing.StartQuery(ctx, "PetPhotoRepository.Lock", sql)
+	defer span.End()
+	rows, err := tx.QueryContext(ctx, sql, petID)
+	helper.PanicIfError(err)
+	helper.PanicIfError(rows.Close())
+}
+
+// Create appends the photo after the pet's last one.
+func (r *PetPhotoRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, photo domain.PetPhoto) domain.PetPhoto {
+	sql := `INSERT INTO pet_photos (pet_id, position, is_primary, content_type, width, height, size_bytes,
+	            original_key, medium_key, thumbnail_key, created_at)
+	        SELECT $1, COALESCE(MAX(position), 0) + 1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+	        FROM pet_photos WHERE pet_id = $1
+	        RETURNING id, position`
+	ctx, span := tracing.StartQuery(ctx, "PetPhotoRepository.Create", sql)
+	defer span.End()
+	err := tx.QueryRowContext(ctx, sql, photo.PetID, photo.Primary, photo.ContentType, photo.Width, photo.Height, photo.SizeBytes,
+		photo.OriginalKey, photo.MediumKey, photo.ThumbnailKey, photo.CreatedAt).Scan(&photo.ID, &photo.Position)
+	helper.PanicIfError(err)
+	return photo
+}
+
+func (r *PetPhotoRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, petID, id int) (domain.PetPhoto, error) {
+	sql := `SELECT ` + petPhotoColumns + ` FROM pet_photos WHERE pet_id=$1 AND id=$2`
+	ctx, span := tracing.StartQuery(ctx, "PetPhotoRepository.FindById", sql)
+	defer span.End()
+	return scanPetPhoto(tx.QueryRowContext(ctx, sql, petID, id))
+}
+
+func (r *PetPhotoRepositoryImpl) FindByPet(ctx context.Context, tx *sql.Tx, petID int) []domain.PetPhoto {
+	return r.FindByPets(ctx, tx, []int{petID})[petID]
+}
+
+func (r *PetPhotoRepositoryImpl) FindByPets(ctx context.Context, tx *sql.Tx, petIDs []int) map[int][]domain.PetPhoto {
+	photos := map[int][]domain.PetPhoto{}
+	if len(petIDs) == 0 {
+		return photos
+	}
+	sql := `SELECT ` + petPhotoColumns + ` FROM pet_photos WHERE pet_id = ANY($1) ORDER BY pet_id, position`
+	ctx, span := tracing.StartQuery(ctx, "PetPhotoRepository.FindByPets", sql)
+	defer span.End()
+	rows, err := tx.QueryContext(ctx, sql, pq.Array(petIDs))
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := scanPetPhoto(rows)
+		helper.PanicIfError(err)
+		photos[p.PetID] = append(photos[p.PetID], p)
+	}
+	helper.PanicIfError(rows.Err())
+	return photos
+}
+
+func (r *PetPhotoRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) []domain.PetPhoto {
+	sql := `SELECT ` + petPhotoColumns + ` FROM pet_photos ORDER BY pet_id, position`
+	ctx, span := tracing.StartQuery(ctx, "PetPhotoRepository.FindAll", sql)
+	defer span.End()
+	rows, err := tx.QueryContext(ctx, sql)
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	var photos []domain.PetPhoto
+	for rows.Next() {
+		p, err := scanPetPhoto(rows)
+		helper.PanicIfError(err)
+		photos = append(photos, p)
+	}
+	helper.PanicIfError(rows.Err())
+	return photos
+}
+
+func (r *PetPhotoRepositoryImpl) SetPrimary(ctx context.Context, tx *sql.Tx, petID, photoID int) {
+	sql := `UPDATE pet_photos SET is_primary = (id = $2) WHERE pet_id = $1 AND (is_primary OR id = $2)`
+	ctx, span := tracing.StartQuery(ctx, "PetPhotoRepository.SetPrimary", sql)
+	defer span.End()
+	_, err := tx.ExecContext(ctx, sql, petID, photoID)
+	helper.PanicIfError(err)
+}
+
+func (r *PetPhotoRepositoryImpl) SetOrder(ctx context.Context, tx *sql.Tx, petID int, photoIDs []int) {
+	sql := `UPDATE pet_photos p SET position = o.position
+	        FROM unnest($2::int[]) WITH ORDINALITY AS o(id, position)
+	        WHERE p.pet_id = $1 AND p.id = o.id`
+	ctx, span := tracing.StartQuery(ctx, "PetPhotoRepository.SetOrder", sql)
+	defer span.End()
+	_, err := tx.ExecContext(ctx, sql, petID, pq.Array(photoIDs))
+	helper.PanicIfError(err)
+}
+
+func (r *PetPhotoRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, photo domain.PetPhoto) {
+	for _, stmt := range []struct {
+		sql  string
+		args []any
+	}{
+		{`DELETE FROM pet_photos WHERE pet_id = $1 AND id = $2`, []any{photo.PetID, photo.ID}},
+		{`UPDATE pet_photos SET position = position - 1 WHERE pet_id = $1 AND position > $2`, []any{photo.PetID, photo.Position}},
+	} {
+		qctx, span := tracing.StartQuery(ctx, "PetPhotoRepository.Delete", stmt.sql)
+		_, err := tx.ExecContext(qctx, stmt.sql, stmt.args...)
+		span.End()
+		helper.PanicIfError(err)
+	}
+}
@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"Go-PetStoreApp/model/domain"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PetQuery filters, sorts and pages a pet listing. Zero filter fields match everything;
+// soft-deleted pets are left out, or are all that is listed with Deleted. After continues the listing past the row with that keyset key (see PetKey), Before
+// returns the rows just ahead of it; otherwise Offset rows are skipped.
+type PetQuery struct {
+	OwnerID  int
+	Species  []string // any of
+	Name     string   // case-insensitive substring
+	MinPrice *float64
+	MaxPrice *float64
+
+	// time ranges are [From, Before)
+	CreatedFrom   time.Time
+	CreatedBefore time.Time
+	UpdatedFrom   time.Time
+	UpdatedBefore time.Time
+
+	Sex         string
+	Color       string // case-insensitive substring
+	Neutered    *bool
+	Microchip   string
+	BornFrom    time.Time // date of birth range, [From, Before)
+	BornBefore  time.Time
+	MinWeightKg *float64
+	MaxWeightKg *float64
+	Deleted     bool
+
+	Sort       []PetSort // nil sorts newest first (id DESC)
+	After      []string
+	Before     []string
+	Offset     int
+	Limit      int
+	CountTotal bool
+}
+
+// PetSort is one ORDER BY key of a listing.
+type PetSort struct {
+	Field string
+	Desc  bool
+}
+
+type petSortField struct {
+	column string
+	key    func(p domain.Pet) string
+	parse  func(s string) (interface{}, error)
+}
+
+func parseText(s string) (interface{}, error) { return s, nil }
+
+func parseTime(s string) (interface{}, error) { return time.Parse(time.RFC3339Nano, s) }
+
+// petSortFields is the whitelist of sortable fields; key and parse round-trip a row's value
+// through a cursor.
+var petSortFields = map[string]petSortField{
+	"id": {"id", func(p domain.Pet) string { return strconv.Itoa(p.ID) },
+		func(s string) (interface{}, error) { return strconv.Atoi(s) }},
+	"name":    {"name", func(p domain.Pet) string { return p.Name }, parseText},
+	"species": {"species", func(p domain.Pet) string { return p.Species }, parseText},
+	"price": {"price", func(p domain.Pet) string { return strconv.FormatFloat(p.Price, 'f', -1, 64) },
+		func(s string) (interface{}, error) { return strconv.ParseFloat(s, 64) }},
+	"created_at": {"created_at", func(p domain.Pet) string { return p.CreatedAt.Format(time.RFC3339Nano) }, parseTime},
+	"updated_at": {"updated_at", func(p domain.Pet) string { return p.UpdatedAt.Format(time.RFC3339Nano) }, parseTime},
+}
+
+// PetSortFields lists the fields accepted by ParsePetSort.
+func PetSortFields() []string {
+	return []string{"id", "name", "species", "price", "created_at", "updated_at"}
+}
+
+// ParsePetSort parses "price,-created_at": comma-separated fields, "-" for descending.
+func ParsePetSort(s string) ([]PetSort, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var sorts []PetSort
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		sort := PetSort{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if _, ok := petSortFields[sort.Field]; !ok {
+			return nil, fmt.Errorf("cannot sort by %q; use %s", sort.Field, strings.Join(PetSortFields(), ", "))
+		}
+		if seen[sort.Field] {
+			return nil, fmt.Errorf("%q is sorted on twice", sort.Field)
+		}
+		seen[sort.Field] = true
+		sorts = append(sorts, sort)
+	}
+	return sorts, nil
+}
+
+// FormatPetSort is the inverse of ParsePetSort.
+func FormatPetSort(sorts []PetSort) string {
+	parts := make([]string, len(sorts))
+	for i, s := range sorts {
+		parts[i] = s.Field
+		if s.Desc {
+			parts[i] = "-" + s.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// PetKey returns p's position in a listing sorted by sorts, for PetQuery.After/Before.
+func PetKey(p domain.Pet, sorts []PetSort) []string {
+	keys := keysetSorts(sorts)
+	values := make([]string, len(keys))
+	for i, s := range keys {
+		values[i] = petSortFields[s.Field].key(p)
+	}
+	return values
+}
+
+// keysetSorts appends id as the final tie-breaker so that the order is total.
+func keysetSorts(sorts []PetSort) []PetSort {
+	for _, s := range sorts {
+		if s.Field == "id" {
+			return sorts
+		}
+	}
+	return append(append([]PetSort{}, sorts...), PetSort{Field: "id", Desc: true})
+}
+
+// petSQL collects the conditions and arguments of a parameterized query.
+type petSQL struct {
+	conds []string
+	args  []interface{}
+}
+
+func (b *petSQL) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+func (b *petSQL) where() string {
+	if len(b.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conds, " AND ")
+}
+
+func (b *petSQL) filter(q PetQuery) {
+	if q.Deleted {
+		b.conds = append(b.conds, "deleted_at IS NOT NULL")
+	} else {
+		b.conds = append(b.conds, "deleted_at IS NULL")
+	}
+	if q.OwnerID != 0 {
+		b.conds = append(b.conds, "created_by = "+b.arg(q.OwnerID))
+	}
+	if len(q.Species) > 0 {
+		params := make([]string, len(q.Species))
+		for i, s := range q.Species {
+			params[i] = b.arg(s)
+		}
+		b.conds = append(b.conds, "species IN ("+strings.Join(params, ", ")+")")
+	}
+	for _, f := range []struct{ column, value string }{{"name", q.Name}, {"color", q.Color}} {
+		if f.value != "" {
+			escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(f.value)
+			b.conds = append(b.conds, f.column+" ILIKE "+b.arg("%"+escaped+"%"))
+		}
+	}
+	for _, f := range []struct{ column, value string }{{"sex", q.Sex}, {"microchip", q.Microchip}} {
+		if f.value != "" {
+			b.conds = append(b.conds, f.column+" = "+b.arg(f.value))
+		}
+	}
+	if q.Neutered != nil {
+		b.conds = append(b.conds, "neutered = "+b.arg(*q.Neutered))
+	}
+	for _, r := range []struct {
+		column   string
+		min, max *float64
+	}{{"price", q.MinPrice, q.MaxPrice}, {"weight_kg", q.MinWeightKg, q.MaxWeightKg}} {
+		if r.min != nil {
+			b.conds = append(b.conds, r.column+" >= "+b.arg(*r.min))
+		}
+		if r.max != nil {
+			b.conds = append(b.conds, r.column+" <= "+b.arg(*r.max))
+		}
+	}
+	for _, r := range []struct {
+		column       string
+		from, before time.Time
+	}{{"created_at", q.CreatedFrom, q.CreatedBefore}, {"updated_at", q.UpdatedFrom, q.UpdatedBefore}} {
+		if !r.from.IsZero() {
+			b.conds = append(b.conds, r.column+" >= "+b.arg(r.from))
+		}
+		if !r.before.IsZero() {
+			b.conds = append(b.conds, r.column+" < "+b.arg(r.before))
+		}
+	}
+	// dates are compared as dates, so the session time zone does not shift them
+	if !q.BornFrom.IsZero() {
+		b.conds = append(b.conds, "date_of_birth >= "+b.arg(q.BornFrom.Format(time.DateOnly))+"::date")
+	}
+	if !q.BornBefore.IsZero() {
+		b.conds = append(b.conds, "date_of_birth < "+b.arg(q.BornBefore.Format(time.DateOnly))+"::date")
+	}
+}
+
+// keyset adds the condition for rows past key in the listing order, or ahead of it when
+// backward, and returns the matching ORDER BY clause.
+func (b *petSQL) keyset(sorts []PetSort, key []string, backward bool) (string, error) {
+	keys := keysetSorts(sorts)
+	order := make([]string, len(keys))
+	for i, s := range keys {
+		desc := s.Desc != backward
+		order[i] = petSortFields[s.Field].column
+		if desc {
+			order[i] += " DESC"
+		}
+	}
+	orderBy := " ORDER BY " + strings.Join(order, ", ")
+	if key == nil {
+		return orderBy, nil
+	}
+	if len(key) != len(keys) {
+		return "", fmt.Errorf("keyset key has %d values for %d sort fields", len(key), len(keys))
+	}
+
+	// (a > $1) OR (a = $1 AND b < $2) OR ... for mixed directions
+	values := make([]string, len(keys))
+	for i, s := range keys {
+		v, err := petSortFields[s.Field].parse(key[i])
+		if err != nil {
+			return "", fmt.Errorf("keyset key %s: %w", s.Field, err)
+		}
+		values[i] = b.arg(v)
+	}
+	var alternatives []string
+	for i, s := range keys {
+		var terms []string
+		for j := 0; j < i; j++ {
+			terms = append(terms, petSortFields[keys[j].Field].column+" = "+values[j])
+		}
+		op := " > "
+		if s.Desc != backward {
+			op = " < "
+		}
+		terms = append(terms, petSortFields[s.Field].column+op+values[i])
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	b.conds = append(b.conds, "("+strings.Join(alternatives, " OR ")+")")
+	return orderBy, nil
+}
@@ -4,12 +4,39 @@ import (
 	"Go-PetStoreApp/model/domain"
 	"context"
 	"database/sql"
+	"time"
 )
 
 type PetRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, pet domain.Pet) domain.Pet
 	FindById(ctx context.Context, tx *sql.Tx, id int) (domain.Pet, error)
-	FindAllWithFilterByUser(ctx context.Context, tx *sql.Tx, userID, limit, offset int, species string) ([]domain.Pet, int)
-	Update(ctx context.Context, tx *sql.Tx, pet domain.Pet) domain.Pet
-	Delete(ctx context.Context, tx *sql.Tx, id int)
+	FindByMicrochip(ctx context.Context, tx *sql.Tx, microchip string) (domain.Pet, error)
+	// FindPage returns up to q.Limit pets in q.Sort order and, if q.CountTotal, how many
+	// pets match the filters in total (-1 otherwise).
+	FindPage(ctx context.Context, tx *sql.Tx, q PetQuery) ([]domain.Pet, int)
+	// Update saves pet if it is still at pet.Version and returns it with its new version;
+	// false means it was changed or deleted since it was read.
+	Update(ctx context.Context, tx *sql.Tx, pet domain.Pet) (domain.Pet, bool)
+	// Touch increments the version of a pet whose photos changed.
+	Touch(ctx context.Context, tx *sql.Tx, id int)
+	// Delete soft-deletes a pet at the given time; finders no longer return it.
+	Delete(ctx context.Context, tx *sql.Tx, id int, at time.Time)
+	// DeleteByOwner soft-deletes every live pet of a user and returns their IDs.
+	DeleteByOwner(ctx context.Context, tx *sql.Tx, ownerID int, at time.Time) []int
+	FindDeletedById(ctx context.Context, tx *sql.Tx, id int) (domain.Pet, error)
+	// Restore undeletes a pet, unless its owner is deleted too; it reports whether it did.
+	Restore(ctx context.Context, tx *sql.Tx, id int) bool
+	// RestoreByOwner undeletes the pets deleted together with their owner at deletedAt and
+	// returns their IDs. Pets whose microchip is meanwhile in use stay deleted.
+	RestoreByOwner(ctx context.Context, tx *sql.Tx, ownerID int, deletedAt time.Time) []int
+	// FindExpired returns up to limit IDs of pets deleted before deletedBefore, or owned by
+	// a user deleted before then, for Purge.
+	FindExpired(ctx context.Context, tx *sql.Tx, deletedBefore time.Time, limit int) []int
+	// Purge hard-deletes pets; their photo rows go with them.
+	Purge(ctx context.Context, tx *sql.Tx, ids []int)
+	// ReassignOwner moves every live pet of fromUserID to toUserID and returns the IDs of the
+	// pets moved. Deleted pets stay with fromUserID, whose restore brings them back.
+	ReassignOwner(ctx context.Context, tx *sql.Tx, fromUserID, toUserID int) []int
+	// DeleteAll removes every pet and restarts the ID sequence.
+	DeleteAll(ctx context.Context, tx *sql.Tx)
 }
\ No newline at end of file
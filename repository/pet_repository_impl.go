@@ -3,92 +3,266 @@ package repository
 import (
 	"Go-PetStoreApp/helper"
 	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/tracing"
 	"context"
 	"database/sql"
-	"strconv"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type PetRepositoryImpl struct{}
 
+// PetColumns are the columns ScanPet reads, in order; other packages that query pets (the
+// search engine) select them too.
+const PetColumns = `id, name, species, breed, price, created_by, created_at, updated_at,
+	date_of_birth, sex, color, weight, weight_unit, neutered, microchip, description, deleted_at, version`
+
+// ScanPet reads PetColumns and then extra, if the query selects more.
+func ScanPet(row interface{ Scan(dest ...any) error }, extra ...any) (domain.Pet, error) {
+	var p domain.Pet
+	var breed, sex, color, weightUnit, microchip, description sql.NullString
+	var dob, deletedAt sql.NullTime
+	var weight sql.NullFloat64
+	var neutered sql.NullBool
+	dest := append([]any{&p.ID, &p.Name, &p.Species, &breed, &p.Price, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt,
+		&dob, &sex, &color, &weight, &weightUnit, &neutered, &microchip, &description, &deletedAt, &p.Version}, extra...)
+	err := row.Scan(dest...)
+	p.Breed = breed.String
+	if dob.Valid {
+		p.DateOfBirth = time.Date(dob.Time.Year(), dob.Time.Month(), dob.Time.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	p.Sex, p.Color, p.Weight, p.WeightUnit = sex.String, color.String, weight.Float64, weightUnit.String
+	if neutered.Valid {
+		p.Neutered = &neutered.Bool
+	}
+	p.Microchip, p.Description = microchip.String, description.String
+	p.DeletedAt = deletedAt.Time
+	return p, err
+}
+
+// petProfileArgs are the profile columns of PetColumns as query arguments, NULL when unknown.
+func petProfileArgs(p domain.Pet) []any {
+	var dob sql.NullString
+	if !p.DateOfBirth.IsZero() {
+		dob = sql.NullString{String: p.DateOfBirth.Format(time.DateOnly), Valid: true}
+	}
+	weight := sql.NullFloat64{Float64: p.Weight, Valid: p.Weight > 0}
+	var neutered sql.NullBool
+	if p.Neutered != nil {
+		neutered = sql.NullBool{Bool: *p.Neutered, Valid: true}
+	}
+	return []any{dob, nullIfEmpty(p.Sex), nullIfEmpty(p.Color), weight, nullIfEmpty(p.WeightUnit), neutered,
+		nullIfEmpty(p.Microchip), nullIfEmpty(p.Description)}
+}
+
+func nullIfEmpty(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
 func NewPetRepository() PetRepository {
 	return &PetRepositoryImpl{}
 }
 
 func (r *PetRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, pet domain.Pet) domain.Pet {
-	sql := `INSERT INTO pets (name, species, price, created_by, created_at, updated_at)
-	        VALUES ($1,$2,$3,$4,$5,$6) RETURNING id`
-	err := tx.QueryRowContext(ctx, sql, pet.Name, pet.Species, pet.Price, pet.CreatedBy, pet.CreatedAt, pet.UpdatedAt).Scan(&pet.ID)
+	sql := `INSERT INTO pets (name, species, breed, price, created_by, created_at, updated_at,
+	        date_of_birth, sex, color, weight, weight_unit, neutered, microchip, description)
+	        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15) RETURNING id, version`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.Create", sql)
+	defer span.End()
+	args := append([]any{pet.Name, pet.Species, nullIfEmpty(pet.Breed), pet.Price, pet.CreatedBy, pet.CreatedAt, pet.UpdatedAt}, petProfileArgs(pet)...)
+	err := tx.QueryRowContext(ctx, sql, args...).Scan(&pet.ID, &pet.Version)
 	helper.PanicIfError(err)
 	return pet
 }
 
 func (r *PetRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, id int) (domain.Pet, error) {
-	sql := `SELECT id, name, species, price, created_by, created_at, updated_at FROM pets WHERE id=$1`
+	sql := `SELECT ` + PetColumns + ` FROM pets WHERE id=$1 AND deleted_at IS NULL`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.FindById", sql)
+	defer span.End()
 	row := tx.QueryRowContext(ctx, sql, id)
-	var pet domain.Pet
-	err := row.Scan(&pet.ID, &pet.Name, &pet.Species, &pet.Price, &pet.CreatedBy, &pet.CreatedAt, &pet.UpdatedAt)
+	pet, err := ScanPet(row)
 	if err != nil {
 		return domain.Pet{}, err
 	}
 	return pet, nil
 }
 
-func (r *PetRepositoryImpl) FindAllWithFilterByUser(ctx context.Context, tx *sql.Tx, userID, limit, offset int, species string) ([]domain.Pet, int) {
-	args := []interface{}{}
-	where := ""
-	argIndex := 1
+func (r *PetRepositoryImpl) FindByMicrochip(ctx context.Context, tx *sql.Tx, microchip string) (domain.Pet, error) {
+	sql := `SELECT ` + PetColumns + ` FROM pets WHERE microchip=$1 AND deleted_at IS NULL`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.FindByMicrochip", sql)
+	defer span.End()
+	return ScanPet(tx.QueryRowContext(ctx, sql, microchip))
+}
+
+func (r *PetRepositoryImpl) FindPage(ctx context.Context, tx *sql.Tx, q PetQuery) ([]domain.Pet, int) {
+	b := &petSQL{}
+	b.filter(q)
 
-	// owner filter
-	if userID != 0 {
-		where += " WHERE created_by = $" + strconv.Itoa(argIndex)
-		args = append(args, userID)
-		argIndex++
+	// count total over the filters only, not the page position
+	total := -1
+	if q.CountTotal {
+		countSQL := "SELECT COUNT(*) FROM pets" + b.where()
+		countCtx, countSpan := tracing.StartQuery(ctx, "PetRepository.FindPage.count", countSQL)
+		err := tx.QueryRowContext(countCtx, countSQL, b.args...).Scan(&total)
+		countSpan.End()
+		helper.PanicIfError(err)
 	}
 
-	// species filter appended
-	if species != "" {
-		if where == "" {
-			where = " WHERE species = $" + strconv.Itoa(argIndex)
-		} else {
-			where += " AND species = $" + strconv.Itoa(argIndex)
-		}
-		args = append(args, species)
-		argIndex++
+	// fetch page; going backwards scans in reverse order and is flipped below
+	backward := q.Before != nil
+	key := q.After
+	if backward {
+		key = q.Before
 	}
-
-	// count total
-	countSQL := "SELECT COUNT(*) FROM pets" + where
-	var total int
-	_ = tx.QueryRowContext(ctx, countSQL, args...).Scan(&total)
-
-	// fetch page
-	args = append(args, limit, offset)
-	limitIdx := argIndex
-	offsetIdx := argIndex + 1
-	dataSQL := "SELECT id, name, species, price, created_by, created_at, updated_at FROM pets" + where + " ORDER BY id DESC LIMIT $" + strconv.Itoa(limitIdx) + " OFFSET $" + strconv.Itoa(offsetIdx)
-	rows, err := tx.QueryContext(ctx, dataSQL, args...)
+	orderBy, err := b.keyset(q.Sort, key, backward)
+	helper.PanicIfError(err)
+	dataSQL := "SELECT " + PetColumns + " FROM pets" + b.where() + orderBy + " LIMIT " + b.arg(q.Limit)
+	if q.Offset > 0 {
+		dataSQL += " OFFSET " + b.arg(q.Offset)
+	}
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.FindPage", dataSQL)
+	defer span.End()
+	rows, err := tx.QueryContext(ctx, dataSQL, b.args...)
 	helper.PanicIfError(err)
 	defer rows.Close()
 
 	var pets []domain.Pet
 	for rows.Next() {
-		var p domain.Pet
-		helper.PanicIfError(rows.Scan(&p.ID, &p.Name, &p.Species, &p.Price, &p.CreatedBy, &p.CreatedAt, &p.UpdatedAt))
+		p, err := ScanPet(rows)
+		helper.PanicIfError(err)
 		pets = append(pets, p)
 	}
+	helper.PanicIfError(rows.Err())
+	if backward {
+		slices.Reverse(pets)
+	}
 	return pets, total
 }
 
-
-func (r *PetRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, pet domain.Pet) domain.Pet {
-	sql := `UPDATE pets SET name=$1, species=$2, price=$3, updated_at=$4 WHERE id=$5`
-	_, err := tx.ExecContext(ctx, sql, pet.Name, pet.Species, pet.Price, pet.UpdatedAt, pet.ID)
+func (r *PetRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, pet domain.Pet) (domain.Pet, bool) {
+	sql := `UPDATE pets SET name=$1, species=$2, breed=$3, price=$4, updated_at=$5,
+	        date_of_birth=$6, sex=$7, color=$8, weight=$9, weight_unit=$10, neutered=$11, microchip=$12, description=$13,
+	        version=version+1
+	        WHERE id=$14 AND version=$15 AND deleted_at IS NULL
+	        RETURNING version`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.Update", sql)
+	defer span.End()
+	args := append([]any{pet.Name, pet.Species, nullIfEmpty(pet.Breed), pet.Price, pet.UpdatedAt}, petProfileArgs(pet)...)
+	rows, err := tx.QueryContext(ctx, sql, append(args, pet.ID, pet.Version)...)
 	helper.PanicIfError(err)
-	return pet
+	defer rows.Close()
+	if !rows.Next() {
+		helper.PanicIfError(rows.Err())
+		return pet, false
+	}
+	helper.PanicIfError(rows.Scan(&pet.Version))
+	return pet, true
 }
 
-func (r *PetRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, id int) {
-	sql := `DELETE FROM pets WHERE id=$1`
+func (r *PetRepositoryImpl) Touch(ctx context.Context, tx *sql.Tx, id int) {
+	sql := `UPDATE pets SET version=version+1 WHERE id=$1`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.Touch", sql)
+	defer span.End()
 	_, err := tx.ExecContext(ctx, sql, id)
 	helper.PanicIfError(err)
 }
+
+func (r *PetRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, id int, at time.Time) {
+	sql := `UPDATE pets SET deleted_at=$2, version=version+1 WHERE id=$1 AND deleted_at IS NULL`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.Delete", sql)
+	defer span.End()
+	_, err := tx.ExecContext(ctx, sql, id, at)
+	helper.PanicIfError(err)
+}
+
+func (r *PetRepositoryImpl) DeleteByOwner(ctx context.Context, tx *sql.Tx, ownerID int, at time.Time) []int {
+	sql := `UPDATE pets SET deleted_at=$2, version=version+1 WHERE created_by=$1 AND deleted_at IS NULL RETURNING id`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.DeleteByOwner", sql)
+	defer span.End()
+	return queryIDs(ctx, tx, sql, ownerID, at)
+}
+
+func (r *PetRepositoryImpl) FindDeletedById(ctx context.Context, tx *sql.Tx, id int) (domain.Pet, error) {
+	sql := `SELECT ` + PetColumns + ` FROM pets WHERE id=$1 AND deleted_at IS NOT NULL`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.FindDeletedById", sql)
+	defer span.End()
+	return ScanPet(tx.QueryRowContext(ctx, sql, id))
+}
+
+func (r *PetRepositoryImpl) Restore(ctx context.Context, tx *sql.Tx, id int) bool {
+	sql := `UPDATE pets SET deleted_at=NULL, version=version+1
+	        WHERE id=$1 AND deleted_at IS NOT NULL
+	        AND created_by IN (SELECT id FROM users WHERE deleted_at IS NULL)`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.Restore", sql)
+	defer span.End()
+	result, err := tx.ExecContext(ctx, sql, id)
+	helper.PanicIfError(err)
+	n, err := result.RowsAffected()
+	helper.PanicIfError(err)
+	return n > 0
+}
+
+func (r *PetRepositoryImpl) RestoreByOwner(ctx context.Context, tx *sql.Tx, ownerID int, deletedAt time.Time) []int {
+	sql := `UPDATE pets p SET deleted_at=NULL, version=p.version+1
+	        WHERE p.created_by=$1 AND p.deleted_at=$2
+	        AND (p.microchip IS NULL OR NOT EXISTS (
+	            SELECT 1 FROM pets live WHERE live.microchip = p.microchip AND live.deleted_at IS NULL))
+	        RETURNING p.id`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.RestoreByOwner", sql)
+	defer span.End()
+	return queryIDs(ctx, tx, sql, ownerID, deletedAt)
+}
+
+func (r *PetRepositoryImpl) FindExpired(ctx context.Context, tx *sql.Tx, deletedBefore time.Time, limit int) []int {
+	sql := `SELECT id FROM pets
+	        WHERE deleted_at < $1 OR created_by IN (SELECT id FROM users WHERE deleted_at < $1)
+	        ORDER BY id LIMIT $2`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.FindExpired", sql)
+	defer span.End()
+	return queryIDs(ctx, tx, sql, deletedBefore, limit)
+}
+
+func (r *PetRepositoryImpl) Purge(ctx context.Context, tx *sql.Tx, ids []int) {
+	sql := `DELETE FROM pets WHERE id = ANY($1)`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.Purge", sql)
+	defer span.End()
+	_, err := tx.ExecContext(ctx, sql, pq.Array(ids))
+	helper.PanicIfError(err)
+}
+
+// queryIDs runs a query that returns one integer column.
+func queryIDs(ctx context.Context, tx *sql.Tx, query string, args ...any) []int {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		helper.PanicIfError(rows.Scan(&id))
+		ids = append(ids, id)
+	}
+	helper.PanicIfError(rows.Err())
+	return ids
+}
+
+func (r *PetRepositoryImpl) ReassignOwner(ctx context.Context, tx *sql.Tx, fromUserID, toUserID int) []int {
+	sql := `UPDATE pets SET created_by=$1, version=version+1 WHERE created_by=$2 AND deleted_at IS NULL RETURNING id`
+	ctx, span := tracing.StartQuery(ctx, "PetRepository.ReassignOwner", sql)
+	defer span.End()
+	return queryIDs(ctx, tx, sql, toUserID, fromUserID)
+}
+
+func (r *PetRepositoryImpl) DeleteAll(ctx context.Context, tx *sql.Tx) {
+	for _, sql := range []string{
+		`DELETE FROM pets`,
+		`SELECT setval(pg_get_serial_sequence('pets', 'id'), 1, false)`,
+	} {
+		qctx, span := tracing.StartQuery(ctx, "PetRepository.DeleteAll", sql)
+		_, err := tx.ExecContext(qctx, sql)
+		span.End()
+		helper.PanicIfError(err)
+	}
+}
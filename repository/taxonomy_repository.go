@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"Go-PetStoreApp/model/domain"
+	"context"
+	"database/sql"
+)
+
+// TaxonomyRepository stores the species and breeds catalog. Name lookups ignore case.
+type TaxonomyRepository interface {
+	FindAllSpecies(ctx context.Context, tx *sql.Tx) []domain.Species
+	FindSpeciesById(ctx context.Context, tx *sql.Tx, id int) (domain.Species, error)
+	FindSpeciesByName(ctx context.Context, tx *sql.Tx, name string) (domain.Species, error)
+	CreateSpecies(ctx context.Context, tx *sql.Tx, species domain.Species) domain.Species
+	// UpdateSpecies renames a species; pets and breeds follow through ON UPDATE CASCADE.
+	UpdateSpecies(ctx context.Context, tx *sql.Tx, species domain.Species) domain.Species
+	DeleteSpecies(ctx context.Context, tx *sql.Tx, id int)
+
+	FindBreedsBySpecies(ctx context.Context, tx *sql.Tx, species string) []domain.Breed
+	FindBreedById(ctx context.Context, tx *sql.Tx, id int) (domain.Breed, error)
+	FindBreedByName(ctx context.Context, tx *sql.Tx, species, name string) (domain.Breed, error)
+	CreateBreed(ctx context.Context, tx *sql.Tx, breed domain.Breed) domain.Breed
+	UpdateBreed(ctx context.Context, tx *sql.Tx, breed domain.Breed) domain.Breed
+	DeleteBreed(ctx context.Context, tx *sql.Tx, id int)
+
+	// CountSpeciesUsage and CountBreedUsage report what still references an entry, so
+	// deletes can be refused before they hit the foreign keys. Soft-deleted pets count, as
+	// they keep their species and breed until purged.
+	CountSpeciesUsage(ctx context.Context, tx *sql.Tx, species string) (pets, breeds int)
+	CountBreedUsage(ctx context.Context, tx *sql.Tx, species, breed string) int
+
+	// FindSpeciesPets and FindBreedPets return the IDs of the pets, deleted ones too, that a
+	// rename of the entry cascades to.
+	FindSpeciesPets(ctx context.Context, tx *sql.Tx, species string) []int
+	FindBreedPets(ctx context.Context, tx *sql.Tx, species, breed string) []int
+}
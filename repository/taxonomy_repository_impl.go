@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/tracing"
+	"context"
+	"database/sql"
+)
+
+type TaxonomyRepositoryImpl struct{}
+
+func NewTaxonomyRepository() TaxonomyRepository {
+	return &TaxonomyRepositoryImpl{}
+}
+
+func (r *TaxonomyRepositoryImpl) FindAllSpecies(ctx context.Context, tx *sql.Tx) []domain.Species {
+	sql := `SELECT id, name, created_at, updated_at FROM species ORDER BY name`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.FindAllSpecies", sql)
+	defer span.End()
+	rows, err := tx.QueryContext(ctx, sql)
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	var species []domain.Species
+	for rows.Next() {
+		var s domain.Species
+		helper.PanicIfError(rows.Scan(&s.ID, &s.Name, &s.CreatedAt, &s.UpdatedAt))
+		species = append(species, s)
+	}
+	helper.PanicIfError(rows.Err())
+	return species
+}
+
+func (r *TaxonomyRepositoryImpl) FindSpeciesById(ctx context.Context, tx *sql.Tx, id int) (domain.Species, error) {
+	sql := `SELECT id, name, created_at, updated_at FROM species WHERE id=$1`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.FindSpeciesById", sql)
+	defer span.End()
+	var s domain.Species
+	err := tx.QueryRowContext(ctx, sql, id).Scan(&s.ID, &s.Name, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+func (r *TaxonomyRepositoryImpl) FindSpeciesByName(ctx context.Context, tx *sql.Tx, name string) (domain.Species, error) {
+	sql := `SELECT id, name, created_at, updated_at FROM species WHERE lower(name)=lower($1)`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.FindSpeciesByName", sql)
+	defer span.End()
+	var s domain.Species
+	err := tx.QueryRowContext(ctx, sql, name).Scan(&s.ID, &s.Name, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+func (r *TaxonomyRepositoryImpl) CreateSpecies(ctx context.Context, tx *sql.Tx, species domain.Species) domain.Species {
+	sql := `INSERT INTO species (name, created_at, updated_at) VALUES ($1, $2, $3) RETURNING id`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.CreateSpecies", sql)
+	defer span.End()
+	err := tx.QueryRowContext(ctx, sql, species.Name, species.CreatedAt, species.UpdatedAt).Scan(&species.ID)
+	helper.PanicIfError(err)
+	return species
+}
+
+func (r *TaxonomyRepositoryImpl) UpdateSpecies(ctx context.Context, tx *sql.Tx, species domain.Species) domain.Species {
+	sql := `UPDATE species SET name=$1, updated_at=$2 WHERE id=$3`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.UpdateSpecies", sql)
+	defer span.End()
+	_, err := tx.ExecContext(ctx, sql, species.Name, species.UpdatedAt, species.ID)
+	helper.PanicIfError(err)
+	return species
+}
+
+func (r *TaxonomyRepositoryImpl) DeleteSpecies(ctx context.Context, tx *sql.Tx, id int) {
+	sql := `DELETE FROM species WHERE id=$1`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.DeleteSpecies", sql)
+	defer span.End()
+	_, err := tx.ExecContext(ctx, sql, id)
+	helper.PanicIfError(err)
+}
+
+func (r *TaxonomyRepositoryImpl) FindBreedsBySpecies(ctx context.Context, tx *sql.Tx, species string) []domain.Breed {
+	sql := `SELECT id, species, name, created_at, updated_at FROM breeds WHERE species=$1 ORDER BY name`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.FindBreedsBySpecies", sql)
+	defer span.End()
+	rows, err := tx.QueryContext(ctx, sql, species)
+	helper.PanicIfError(err)
+	defer rows.Close()
+
+	var breeds []domain.Breed
+	for rows.Next() {
+		var b domain.Breed
+		helper.PanicIfError(rows.Scan(&b.ID, &b.Species, &b.Name, &b.CreatedAt, &b.UpdatedAt))
+		breeds = append(breeds, b)
+	}
+	helper.PanicIfError(rows.Err())
+	return breeds
+}
+
+func (r *TaxonomyRepositoryImpl) FindBreedById(ctx context.Context, tx *sql.Tx, id int) (domain.Breed, error) {
+	sql := `SELECT id, species, name, created_at, updated_at FROM breeds WHERE id=$1`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.FindBreedById", sql)
+	defer span.End()
+	var b domain.Breed
+	err := tx.QueryRowContext(ctx, sql, id).Scan(&b.ID, &b.Species, &b.Name, &b.CreatedAt, &b.UpdatedAt)
+	return b, err
+}
+
+func (r *TaxonomyRepositoryImpl) FindBreedByName(ctx context.Context, tx *sql.Tx, species, name string) (domain.Breed, error) {
+	sql := `SELECT id, species, name, created_at, updated_at FROM breeds WHERE species=$1 AND lower(name)=lower($2)`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.FindBreedByName", sql)
+	defer span.End()
+	var b domain.Breed
+	err := tx.QueryRowContext(ctx, sql, species, name).Scan(&b.ID, &b.Species, &b.Name, &b.CreatedAt, &b.UpdatedAt)
+	return b, err
+}
+
+func (r *TaxonomyRepositoryImpl) CreateBreed(ctx context.Context, tx *sql.Tx, breed domain.Breed) domain.Breed {
+	sql := `INSERT INTO breeds (species, name, created_at, updated_at) VALUES ($1, $2, $3, $4) RETURNING id`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.CreateBreed", sql)
+	defer span.End()
+	err := tx.QueryRowContext(ctx, sql, breed.Species, breed.Name, breed.CreatedAt, breed.UpdatedAt).Scan(&breed.ID)
+	helper.PanicIfError(err)
+	return breed
+}
+
+func (r *TaxonomyRepositoryImpl) UpdateBreed(ctx context.Context, tx *sql.Tx, breed domain.Breed) domain.Breed {
+	sql := `UPDATE breeds SET name=$1, updated_at=$2 WHERE id=$3`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.UpdateBreed", sql)
+	defer span.End()
+	_, err := tx.ExecContext(ctx, sql, breed.Name, breed.UpdatedAt, breed.ID)
+	helper.PanicIfError(err)
+	return breed
+}
+
+func (r *TaxonomyRepositoryImpl) DeleteBreed(ctx context.Context, tx *sql.Tx, id int) {
+	sql := `DELETE FROM breeds WHERE id=$1`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.DeleteBreed", sql)
+	defer span.End()
+	_, err := tx.ExecContext(ctx, sql, id)
+	helper.PanicIfError(err)
+}
+
+func (r *TaxonomyRepositoryImpl) CountSpeciesUsage(ctx context.Context, tx *sql.Tx, species string) (int, int) {
+	sql := `SELECT (SELECT COUNT(*) FROM pets WHERE species=$1), (SELECT COUNT(*) FROM breeds WHERE species=$1)`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.CountSpeciesUsage", sql)
+	defer span.End()
+	var pets, breeds int
+	helper.PanicIfError(tx.QueryRowContext(ctx, sql, species).Scan(&pets, &breeds))
+	return pets, breeds
+}
+
+func (r *TaxonomyRepositoryImpl) CountBreedUsage(ctx context.Context, tx *sql.Tx, species, breed string) int {
+	sql := `SELECT COUNT(*) FROM pets WHERE species=$1 AND breed=$2`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.CountBreedUsage", sql)
+	defer span.End()
+	var pets int
+	helper.PanicIfError(tx.QueryRowContext(ctx, sql, species, breed).Scan(&pets))
+	return pets
+}
+
+func (r *TaxonomyRepositoryImpl) FindSpeciesPets(ctx context.Context, tx *sql.Tx, species string) []int {
+	sql := `SELECT id FROM pets WHERE species=$1 ORDER BY id`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.FindSpeciesPets", sql)
+	defer span.End()
+	return queryIDs(ctx, tx, sql, species)
+}
+
+func (r *TaxonomyRepositoryImpl) FindBreedPets(ctx context.Context, tx *sql.Tx, species, breed string) []int {
+	sql := `SELECT id FROM pets WHERE species=$1 AND breed=$2 ORDER BY id`
+	ctx, span := tracing.StartQuery(ctx, "TaxonomyRepository.FindBreedPets", sql)
+	defer span.End()
+	return queryIDs(ctx, tx, sql, species, breed)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 	"Go-PetStoreApp/model/domain"
 )
 
@@ -10,8 +11,32 @@ type UserRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, user domain.User) (domain.User, error)
 	FindByEmail(ctx context.Context, tx *sql.Tx, email string) (domain.User, error)
 	FindByUsername(ctx context.Context, tx *sql.Tx, username string) (domain.User, error)
+	// UsernameExists also counts soft-deleted users, which FindByUsername does not see.
+	UsernameExists(ctx context.Context, tx *sql.Tx, username string) (bool, error)
 	FindById(ctx context.Context, tx *sql.Tx, id int) (domain.User, error)
+	// Lock is FindById that also keeps the user from being changed or deleted until tx ends.
+	Lock(ctx context.Context, tx *sql.Tx, id int) (domain.User, error)
 	FindAll(ctx context.Context, tx *sql.Tx) ([]domain.User, error)
+	// Update saves user if it is still at user.Version and returns it with its new version;
+	// sql.ErrNoRows means it was changed or deleted since it was read.
 	Update(ctx context.Context, tx *sql.Tx, user domain.User) (domain.User, error)
-	Delete(ctx context.Context, tx *sql.Tx, id int) error
+	// Delete soft-deletes a user at the given time; the finders above no longer return it.
+	Delete(ctx context.Context, tx *sql.Tx, id int, at time.Time) error
+	// FindDeleted returns the soft-deleted users, most recently deleted first.
+	FindDeleted(ctx context.Context, tx *sql.Tx) ([]domain.User, error)
+	FindDeletedById(ctx context.Context, tx *sql.Tx, id int) (domain.User, error)
+	Restore(ctx context.Context, tx *sql.Tx, id int) error
+	// Purge hard-deletes the users deleted before deletedBefore and returns their IDs. Users
+	// that still own pets, even deleted ones, are skipped: their pets must be purged first.
+	Purge(ctx context.Context, tx *sql.Tx, deletedBefore time.Time) ([]int, error)
+	// UpdatePassword and UpdateRole change a user that is still at version, like Update;
+	// sql.ErrNoRows means it was changed or deleted since it was read.
+	UpdatePassword(ctx context.Context, tx *sql.Tx, id, version int, passwordHash string) error
+	UpdateRole(ctx context.Context, tx *sql.Tx, id, version int, role string) error
+	RevokeTokens(ctx context.Context, tx *sql.Tx, id int, before time.Time) error
+	// TokensRevokedBefore returns the zero time when the user's tokens were never revoked.
+	TokensRevokedBefore(ctx context.Context, tx *sql.Tx, id int) (time.Time, error)
+	// DeleteAll removes every user and token revocation and restarts the ID sequence;
+	// pets must be deleted first.
+	DeleteAll(ctx context.Context, tx *sql.Tx) error
 }
\ No newline at end of file
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/tracing"
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 )
 
 type UserRepositoryImpl struct{}
@@ -17,8 +19,10 @@ func (r *UserRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, user domain
 	query := `
 		INSERT INTO users (username, email, password_hash, role, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at, role
+		RETURNING id, created_at, updated_at, role, version
 	`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.Create", query)
+	defer span.End()
 	row := tx.QueryRowContext(ctx, query,
 		user.Username,
 		user.Email,
@@ -28,7 +32,7 @@ func (r *UserRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, user domain
 		user.UpdatedAt,
 	)
 
-	err := row.Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt, &user.Role)
+	err := row.Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt, &user.Role, &user.Version)
 	if err != nil {
 		return domain.User{}, err
 	}
@@ -36,10 +40,12 @@ func (r *UserRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, user domain
 }
 
 func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, tx *sql.Tx, email string) (domain.User, error) {
-	query := `SELECT id, username, email, password_hash, role, created_at, updated_at FROM users WHERE email=$1`
+	query := `SELECT id, username, email, password_hash, role, created_at, updated_at, version FROM users WHERE email=$1 AND deleted_at IS NULL`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.FindByEmail", query)
+	defer span.End()
 	row := tx.QueryRowContext(ctx, query, email)
 	var u domain.User
-	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt)
+	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt, &u.Version)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.User{}, sql.ErrNoRows
@@ -50,10 +56,12 @@ func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, tx *sql.Tx, email
 }
 
 func (r *UserRepositoryImpl) FindByUsername(ctx context.Context, tx *sql.Tx, username string) (domain.User, error) {
-	query := `SELECT id, username, email, password_hash, role, created_at, updated_at FROM users WHERE username=$1`
+	query := `SELECT id, username, email, password_hash, role, created_at, updated_at, version FROM users WHERE username=$1 AND deleted_at IS NULL`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.FindByUsername", query)
+	defer span.End()
 	row := tx.QueryRowContext(ctx, query, username)
 	var u domain.User
-	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt)
+	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt, &u.Version)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.User{}, sql.ErrNoRows
@@ -63,11 +71,22 @@ func (r *UserRepositoryImpl) FindByUsername(ctx context.Context, tx *sql.Tx, use
 	return u, nil
 }
 
+func (r *UserRepositoryImpl) UsernameExists(ctx context.Context, tx *sql.Tx, username string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM users WHERE username=$1)`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.UsernameExists", query)
+	defer span.End()
+	var exists bool
+	err := tx.QueryRowContext(ctx, query, username).Scan(&exists)
+	return exists, err
+}
+
 func (r *UserRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, id int) (domain.User, error) {
-	query := `SELECT id, username, email, password_hash, role, created_at, updated_at FROM users WHERE id=$1`
+	query := `SELECT id, username, email, password_hash, role, created_at, updated_at, version FROM users WHERE id=$1 AND deleted_at IS NULL`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.FindById", query)
+	defer span.End()
 	row := tx.QueryRowContext(ctx, query, id)
 	var u domain.User
-	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt)
+	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt, &u.Version)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.User{}, sql.ErrNoRows
@@ -77,9 +96,22 @@ func (r *UserRepositoryImpl) FindById(ctx context.Context, tx *sql.Tx, id int) (
 	return u, nil
 }
 
+func (r *UserRepositoryImpl) Lock(ctx context.Context, tx *sql.Tx, id int) (domain.User, error) {
+	query := `SELECT id, username, email, password_hash, role, created_at, updated_at, version FROM users WHERE id=$1 AND deleted_at IS NULL FOR SHARE`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.Lock", query)
+	defer span.End()
+	var u domain.User
+	err := tx.QueryRowContext(ctx, query, id).Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt, &u.Version)
+	if err != nil {
+		return domain.User{}, err
+	}
+	return u, nil
+}
 
 func (r *UserRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) ([]domain.User, error) {
-	query := `SELECT id, username, email, password_hash, role, created_at, updated_at FROM users`
+	query := `SELECT id, username, email, password_hash, role, created_at, updated_at, version FROM users WHERE deleted_at IS NULL`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.FindAll", query)
+	defer span.End()
 	rows, err := tx.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -89,7 +121,7 @@ func (r *UserRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) ([]domain.
 	var users []domain.User
 	for rows.Next() {
 		var u domain.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt, &u.Version); err != nil {
 			return nil, err
 		}
 		users = append(users, u)
@@ -98,16 +130,141 @@ func (r *UserRepositoryImpl) FindAll(ctx context.Context, tx *sql.Tx) ([]domain.
 }
 
 func (r *UserRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, user domain.User) (domain.User, error) {
-	query := `UPDATE users SET username=$1, email=$2, updated_at=$3 WHERE id=$4 RETURNING updated_at`
-	err := tx.QueryRowContext(ctx, query, user.Username, user.Email, user.UpdatedAt, user.ID).Scan(&user.UpdatedAt)
+	query := `UPDATE users SET username=$1, email=$2, updated_at=$3, version=version+1
+		WHERE id=$4 AND version=$5 AND deleted_at IS NULL RETURNING updated_at, version`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.Update", query)
+	defer span.End()
+	err := tx.QueryRowContext(ctx, query, user.Username, user.Email, user.UpdatedAt, user.ID, user.Version).Scan(&user.UpdatedAt, &user.Version)
 	if err != nil {
 		return domain.User{}, err
 	}
 	return user, nil
 }
 
-func (r *UserRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, id int) error {
-	query := `DELETE FROM users WHERE id=$1`
+func (r *UserRepositoryImpl) Delete(ctx context.Context, tx *sql.Tx, id int, at time.Time) error {
+	query := `UPDATE users SET deleted_at=$2, version=version+1 WHERE id=$1 AND deleted_at IS NULL`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.Delete", query)
+	defer span.End()
+	_, err := tx.ExecContext(ctx, query, id, at)
+	return err
+}
+
+func (r *UserRepositoryImpl) FindDeleted(ctx context.Context, tx *sql.Tx) ([]domain.User, error) {
+	query := `SELECT id, username, email, password_hash, role, created_at, updated_at, deleted_at, version FROM users
+		WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.FindDeleted", query)
+	defer span.End()
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []domain.User
+	for rows.Next() {
+		var u domain.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt, &u.DeletedAt, &u.Version); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepositoryImpl) FindDeletedById(ctx context.Context, tx *sql.Tx, id int) (domain.User, error) {
+	query := `SELECT id, username, email, password_hash, role, created_at, updated_at, deleted_at, version FROM users
+		WHERE id=$1 AND deleted_at IS NOT NULL`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.FindDeletedById", query)
+	defer span.End()
+	var u domain.User
+	err := tx.QueryRowContext(ctx, query, id).Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.Role, &u.CreatedAt, &u.UpdatedAt, &u.DeletedAt, &u.Version)
+	if err != nil {
+		return domain.User{}, err
+	}
+	return u, nil
+}
+
+func (r *UserRepositoryImpl) Restore(ctx context.Context, tx *sql.Tx, id int) error {
+	query := `UPDATE users SET deleted_at=NULL, version=version+1 WHERE id=$1 AND deleted_at IS NOT NULL`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.Restore", query)
+	defer span.End()
 	_, err := tx.ExecContext(ctx, query, id)
 	return err
 }
+
+// Purge relies on ON DELETE CASCADE for the users' token revocations; pets restrict the delete.
+func (r *UserRepositoryImpl) Purge(ctx context.Context, tx *sql.Tx, deletedBefore time.Time) ([]int, error) {
+	query := `DELETE FROM users u WHERE deleted_at < $1 AND NOT EXISTS (SELECT 1 FROM pets WHERE created_by=u.id) RETURNING id`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.Purge", query)
+	defer span.End()
+	rows, err := tx.QueryContext(ctx, query, deletedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *UserRepositoryImpl) UpdatePassword(ctx context.Context, tx *sql.Tx, id, version int, passwordHash string) error {
+	query := `UPDATE users SET password_hash=$1, version=version+1
+		WHERE id=$2 AND version=$3 AND deleted_at IS NULL RETURNING id`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.UpdatePassword", query)
+	defer span.End()
+	return tx.QueryRowContext(ctx, query, passwordHash, id, version).Scan(&id)
+}
+
+func (r *UserRepositoryImpl) UpdateRole(ctx context.Context, tx *sql.Tx, id, version int, role string) error {
+	query := `UPDATE users SET role=$1, version=version+1
+		WHERE id=$2 AND version=$3 AND deleted_at IS NULL RETURNING id`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.UpdateRole", query)
+	defer span.End()
+	return tx.QueryRowContext(ctx, query, role, id, version).Scan(&id)
+}
+
+func (r *UserRepositoryImpl) RevokeTokens(ctx context.Context, tx *sql.Tx, id int, before time.Time) error {
+	query := `INSERT INTO token_revocations (user_id, revoked_before) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET revoked_before = EXCLUDED.revoked_before`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.RevokeTokens", query)
+	defer span.End()
+	_, err := tx.ExecContext(ctx, query, id, before)
+	return err
+}
+
+func (r *UserRepositoryImpl) TokensRevokedBefore(ctx context.Context, tx *sql.Tx, id int) (time.Time, error) {
+	query := `SELECT revoked_before FROM token_revocations WHERE user_id=$1`
+	ctx, span := tracing.StartQuery(ctx, "UserRepository.TokensRevokedBefore", query)
+	defer span.End()
+	var before time.Time
+	err := tx.QueryRowContext(ctx, query, id).Scan(&before)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return before, err
+}
+
+// DeleteAll uses DELETE rather than TRUNCATE, which Postgres refuses for tables that other
+// tables reference unless they are truncated in the same statement.
+func (r *UserRepositoryImpl) DeleteAll(ctx context.Context, tx *sql.Tx) error {
+	for _, query := range []string{
+		`DELETE FROM token_revocations`,
+		`DELETE FROM users`,
+		`SELECT setval(pg_get_serial_sequence('users', 'id'), 1, false)`,
+	} {
+		qctx, span := tracing.StartQuery(ctx, "UserRepository.DeleteAll", query)
+		_, err := tx.ExecContext(qctx, query)
+		span.End()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"html"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"Go-PetStoreApp/model/domain"
+)
+
+// fieldWeights rank a match in the name above one in the species or breed, then the color
+// and the description, like the A-D weights of the Postgres search vector.
+var fieldWeights = []float64{1.0, 0.4, 0.4, 0.2, 0.1}
+
+// Memory is a simple in-process index without stemming: a term matches a word it equals
+// or, for the last term, a word it is a prefix of. It holds every indexed pet in memory.
+type Memory struct {
+	mu   sync.RWMutex
+	pets map[int]domain.Pet
+}
+
+func NewMemory() *Memory {
+	return &Memory{pets: map[int]domain.Pet{}}
+}
+
+func (e *Memory) Index(pet domain.Pet) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pets[pet.ID] = pet
+}
+
+func (e *Memory) Remove(petID int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.pets, petID)
+}
+
+func (e *Memory) Search(_ context.Context, _ *sql.Tx, q Query) ([]Hit, int, error) {
+	terms := Terms(q.Text)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+
+	e.mu.RLock()
+	var hits []Hit
+	for _, pet := range e.pets {
+		if q.OwnerID != 0 && pet.CreatedBy != q.OwnerID {
+			continue
+		}
+		if rank, ok := score(fieldValues(pet), terms); ok {
+			hits = append(hits, Hit{Pet: pet, Rank: rank})
+		}
+	}
+	e.mu.RUnlock()
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].Pet.ID > hits[j].Pet.ID
+	})
+	total := len(hits)
+	if q.Offset >= len(hits) {
+		return nil, total, nil
+	}
+	hits = hits[q.Offset:]
+	if len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	for i := range hits {
+		hits[i].Highlights = map[string]string{}
+		for f, value := range fieldValues(hits[i].Pet) {
+			hits[i].Highlights[Fields[f]] = highlight(value, terms)
+		}
+	}
+	return hits, total, nil
+}
+
+// score requires every term to match some field and sums the weights of the matches.
+func score(values []string, terms []string) (float64, bool) {
+	rank := 0.0
+	for t, term := range terms {
+		matched := false
+		for f, value := range values {
+			for _, word := range Terms(value) {
+				if matches(word, term, t == len(terms)-1) {
+					rank += fieldWeights[f]
+					matched = true
+				}
+			}
+		}
+		if !matched {
+			return 0, false
+		}
+	}
+	return rank, true
+}
+
+func matches(word, term string, prefix bool) bool {
+	return word == term || prefix && strings.HasPrefix(word, term)
+}
+
+// highlight HTML-escapes text and marks the words that match terms.
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+	word := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		for j < len(runes) && word(runes[j]) == word(runes[i]) {
+			j++
+		}
+		chunk := string(runes[i:j])
+		marked := false
+		if word(runes[i]) {
+			lower := strings.ToLower(chunk)
+			for t, term := range terms {
+				if matches(lower, term, t == len(terms)-1) {
+					marked = true
+					break
+				}
+			}
+		}
+		if marked {
+			b.WriteString("<mark>" + html.EscapeString(chunk) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(chunk))
+		}
+		i = j
+	}
+	return b.String()
+}
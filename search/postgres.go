@@ -0,0 +1,105 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/repository"
+	"Go-PetStoreApp/tracing"
+)
+
+// Postgres searches pets.search_vector, which a trigger keeps up to date (migration 0005).
+type Postgres struct{}
+
+func NewPostgres() *Postgres {
+	return &Postgres{}
+}
+
+// headline escapes a column for HTML before ts_headline marks the matches in it. Short
+// fields are returned whole; long ones are cut down to the fragments around the matches.
+func headline(column string, long bool) string {
+	escaped := "replace(replace(replace(coalesce(" + column + ", ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+	options := "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	if long {
+		options = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=8"
+	}
+	return "ts_headline('english', " + escaped + ", q, '" + options + "')"
+}
+
+func (e *Postgres) Search(ctx context.Context, tx *sql.Tx, q Query) ([]Hit, int, error) {
+	tsquery := toTSQuery(q.Text)
+	if tsquery == "" {
+		return nil, 0, nil
+	}
+	args := []interface{}{tsquery}
+	where := "search_vector @@ q AND deleted_at IS NULL"
+	if q.OwnerID != 0 {
+		args = append(args, q.OwnerID)
+		where += " AND created_by = $2"
+	}
+
+	countSQL := "SELECT COUNT(*) FROM pets, to_tsquery('english', $1) q WHERE " + where
+	countCtx, countSpan := tracing.StartQuery(ctx, "PetSearch.Search.count", countSQL)
+	var total int
+	err := tx.QueryRowContext(countCtx, countSQL, args...).Scan(&total)
+	countSpan.End()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, q.Limit, q.Offset)
+	n := len(args)
+	dataSQL := `SELECT ` + repository.PetColumns + `,
+	        ts_rank(search_vector, q) AS rank, ` + headline("name", false) + `, ` + headline("species", false) + `,
+	        ` + headline("breed", false) + `, ` + headline("color", false) + `, ` + headline("description", true) + `
+	        FROM pets, to_tsquery('english', $1) q
+	        WHERE ` + where + `
+	        ORDER BY rank DESC, id DESC
+	        LIMIT $` + strconv.Itoa(n-1) + ` OFFSET $` + strconv.Itoa(n)
+	ctx, span := tracing.StartQuery(ctx, "PetSearch.Search", dataSQL)
+	defer span.End()
+	rows, err := tx.QueryContext(ctx, dataSQL, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var hits []Hit
+	for rows.Next() {
+		var h Hit
+		highlights := make([]string, len(Fields))
+		extra := []any{&h.Rank}
+		for i := range highlights {
+			extra = append(extra, &highlights[i])
+		}
+		pet, err := repository.ScanPet(rows, extra...)
+		if err != nil {
+			return nil, 0, err
+		}
+		h.Pet = pet
+		h.Highlights = map[string]string{}
+		for i, field := range Fields {
+			h.Highlights[field] = highlights[i]
+		}
+		hits = append(hits, h)
+	}
+	return hits, total, rows.Err()
+}
+
+func (e *Postgres) Index(domain.Pet) {}
+
+func (e *Postgres) Remove(int) {}
+
+// toTSQuery turns user text into "fluffy & orange & kitten:*". Terms are reduced to letters
+// and digits, so no tsquery syntax gets through.
+func toTSQuery(text string) string {
+	terms := Terms(text)
+	if len(terms) == 0 {
+		return ""
+	}
+	terms[len(terms)-1] += ":*"
+	return strings.Join(terms, " & ")
+}
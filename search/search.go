@@ -0,0 +1,52 @@
+// Package search finds pets by free text. Engine is implemented by Postgres full-text search
+// for production and by an in-memory index for development without a search-capable schema.
+package search
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode"
+
+	"Go-PetStoreApp/model/domain"
+)
+
+// Query is a free-text search. Every term must match; the last one also matches as a prefix
+// so results follow the user while they type.
+type Query struct {
+	Text    string
+	OwnerID int // 0 searches every owner's pets
+	Limit   int
+	Offset  int
+}
+
+// Hit is one matching pet. Highlights holds, per searched field, its text HTML-escaped with
+// the matching words wrapped in <mark></mark>.
+type Hit struct {
+	Pet        domain.Pet
+	Rank       float64
+	Highlights map[string]string
+}
+
+type Engine interface {
+	// Search returns one page of hits, best first, and the total number of matches.
+	Search(ctx context.Context, tx *sql.Tx, q Query) ([]Hit, int, error)
+	// Index and Remove keep the engine in step with pet writes. The Postgres engine ignores
+	// them because its index is maintained by a trigger.
+	Index(pet domain.Pet)
+	Remove(petID int)
+}
+
+// Fields are the pet fields that are searched, in weight order.
+var Fields = []string{"name", "species", "breed", "color", "description"}
+
+func fieldValues(p domain.Pet) []string {
+	return []string{p.Name, p.Species, p.Breed, p.Color, p.Description}
+}
+
+// Terms splits text into lower-case words of letters and digits; everything else separates.
+func Terms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,50 @@
+package seed
+
+type speciesRange struct {
+	name                 string
+	minPrice, maxPrice   float64
+	minWeight, maxWeight float64 // kg
+	maxAge               int     // years
+}
+
+var species = []speciesRange{
+	{"dog", 150, 4000, 2, 60, 14},
+	{"cat", 80, 2500, 2.5, 8, 16},
+	{"rabbit", 30, 300, 1, 5, 9},
+	{"hamster", 10, 60, 0.03, 0.15, 2},
+	{"guinea pig", 20, 90, 0.7, 1.2, 6},
+	{"ferret", 100, 500, 0.7, 2, 7},
+	{"parrot", 200, 3500, 0.1, 1.5, 40},
+	{"canary", 25, 150, 0.015, 0.03, 10},
+	{"goldfish", 1, 40, 0.01, 0.3, 10},
+	{"koi", 20, 1500, 0.5, 9, 30},
+	{"turtle", 20, 400, 0.2, 10, 40},
+	{"snake", 50, 1200, 0.1, 15, 20},
+	{"lizard", 30, 800, 0.05, 5, 15},
+	{"hedgehog", 150, 600, 0.3, 0.6, 5},
+	{"horse", 1500, 25000, 300, 700, 25},
+}
+
+var colors = []string{
+	"black", "white", "brown", "gray", "golden", "cream", "orange", "tabby", "spotted", "black and white",
+}
+
+var petNames = []string{
+	"Bella", "Luna", "Charlie", "Max", "Lucy", "Cooper", "Daisy", "Milo", "Bailey", "Rocky",
+	"Coco", "Oliver", "Nala", "Leo", "Simba", "Zoe", "Teddy", "Loki", "Pepper", "Ginger",
+	"Biscuit", "Mochi", "Shadow", "Willow", "Ziggy", "Nemo", "Sunny", "Pickles", "Olive", "Bruno",
+	"Hazel", "Maple", "Juniper", "Clover", "Peanut", "Waffles", "Kiwi", "Mango", "Sushi", "Tofu",
+}
+
+var firstNames = []string{
+	"alex", "sam", "jordan", "taylor", "casey", "riley", "morgan", "jamie", "avery", "quinn",
+	"ana", "budi", "chen", "dewi", "emeka", "farah", "goran", "hana", "ines", "jonas",
+	"kofi", "lena", "mateo", "nia", "omar", "priya", "rosa", "sven", "tomas", "yuki",
+}
+
+var lastNames = []string{
+	"smith", "garcia", "nguyen", "kim", "santoso", "okafor", "silva", "novak", "ivanova", "haddad",
+	"muller", "rossi", "tanaka", "wijaya", "kowalski", "dubois", "jensen", "costa", "ali", "patel",
+}
+
+var emailDomains = []string{"example.com", "example.org", "example.net"}
@@ -0,0 +1,231 @@
+// Package seed fills a development database with deterministic fixtures through the
+// repository layer. The same Options always produce the same users and pets, and users that
+// already exist are skipped with their pets, so interrupted or repeated runs are safe.
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"Go-PetStoreApp/blob"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/logx"
+	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// AdminUsername is created alongside the regular fixtures, with the same password.
+const AdminUsername = "seed_admin"
+
+type Options struct {
+	Seed     int64
+	Users    int
+	Pets     int    // spread evenly over the users
+	Password string // shared by every seeded account
+	// Reset deletes all pets, with their photo files, and users (not only seeded ones) first,
+	// in dependency order.
+	Reset bool
+	// Progress, if set, is called after each user.
+	Progress func(done, total int)
+}
+
+type Result struct {
+	UsersCreated int `json:"users_created"`
+	UsersSkipped int `json:"users_skipped"`
+	PetsCreated  int `json:"pets_created"`
+}
+
+// epoch anchors generated timestamps so they don't depend on when seeding runs.
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type Seeder struct {
+	DB                 *sql.DB
+	UserRepository     repository.UserRepository
+	PetRepository      repository.PetRepository
+	PetPhotoRepository repository.PetPhotoRepository
+	BlobStore          blob.Store
+}
+
+func NewSeeder(db *sql.DB, userRepository repository.UserRepository, petRepository repository.PetRepository, photoRepository repository.PetPhotoRepository, store blob.Store) *Seeder {
+	return &Seeder{DB: db, UserRepository: userRepository, PetRepository: petRepository, PetPhotoRepository: photoRepository, BlobStore: store}
+}
+
+func (s *Seeder) Run(ctx context.Context, opts Options) (result Result, err error) {
+	if opts.Users < 0 || opts.Pets < 0 {
+		return result, fmt.Errorf("seed: --users and --pets must not be negative")
+	}
+	if opts.Pets > 0 && opts.Users == 0 {
+		return result, fmt.Errorf("seed: pets need at least one user")
+	}
+	defer func() {
+		// repositories panic on database errors
+		if r := recover(); r != nil {
+			err = fmt.Errorf("seed: %v", r)
+		}
+	}()
+
+	if opts.Reset {
+		if err := s.reset(ctx); err != nil {
+			return result, err
+		}
+	}
+
+	// bcrypt is deliberately slow; hashing once keeps large runs fast
+	hash, err := bcrypt.GenerateFromPassword([]byte(opts.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return result, err
+	}
+
+	admin := domain.User{Username: AdminUsername, Email: AdminUsername + "@example.com", PasswordHash: string(hash), Role: "admin", CreatedAt: epoch, UpdatedAt: epoch}
+	created, err := s.createUser(ctx, admin, nil)
+	if err != nil {
+		return result, err
+	}
+	if !created {
+		result.UsersSkipped++
+	} else {
+		result.UsersCreated++
+	}
+
+	for i := 0; i < opts.Users; i++ {
+		// one RNG per user: a user's fixtures don't change when --users grows
+		rng := rand.New(rand.NewSource(opts.Seed*1_000_003 + int64(i)))
+		user := fakeUser(rng, i, string(hash))
+		petCount := opts.Pets / opts.Users
+		if i < opts.Pets%opts.Users {
+			petCount++
+		}
+		pets := make([]domain.Pet, petCount)
+		for j := range pets {
+			pets[j] = fakePet(rng, user.CreatedAt)
+		}
+
+		created, err := s.createUser(ctx, user, pets)
+		if err != nil {
+			return result, err
+		}
+		if created {
+			result.UsersCreated++
+			result.PetsCreated += len(pets)
+		} else {
+			result.UsersSkipped++
+		}
+		if opts.Progress != nil {
+			opts.Progress(i+1, opts.Users)
+		}
+	}
+	return result, nil
+}
+
+// reset deletes every pet and user, and once the rows are gone the files of the pets'
+// photos, which the rows cascade to; a file that fails to delete is only wasted space.
+func (s *Seeder) reset(ctx context.Context) error {
+	var keys []string
+	if err := s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, photo := range s.PetPhotoRepository.FindAll(ctx, tx) {
+			keys = append(keys, photo.OriginalKey, photo.MediumKey, photo.ThumbnailKey)
+		}
+		s.PetRepository.DeleteAll(ctx, tx)
+		return s.UserRepository.DeleteAll(ctx, tx)
+	}); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := s.BlobStore.Delete(ctx, key); err != nil {
+			logx.FromContext(ctx).Warn("deleting photo file", "key", key, "error", err)
+		}
+	}
+	return nil
+}
+
+// createUser inserts user and pets in one transaction unless the username already exists,
+// also as a soft-deleted user: seeding must neither duplicate nor resurrect it.
+func (s *Seeder) createUser(ctx context.Context, user domain.User, pets []domain.Pet) (bool, error) {
+	created := false
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		exists, err := s.UserRepository.UsernameExists(ctx, tx, user.Username)
+		if err != nil || exists {
+			return err
+		}
+		u, err := s.UserRepository.Create(ctx, tx, user)
+		if err != nil {
+			return err
+		}
+		for _, pet := range pets {
+			pet.CreatedBy = u.ID
+			s.PetRepository.Create(ctx, tx, pet)
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+func (s *Seeder) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func fakeUser(rng *rand.Rand, i int, passwordHash string) domain.User {
+	first := firstNames[rng.Intn(len(firstNames))]
+	last := lastNames[rng.Intn(len(lastNames))]
+	// the index keeps names unique and makes re-runs find the same rows
+	username := fmt.Sprintf("%s_%s_%d", first, last, i+1)
+	created := epoch.Add(-time.Duration(rng.Int63n(int64(2 * 365 * 24 * time.Hour))))
+	role := "user"
+	if rng.Intn(50) == 0 {
+		role = "admin"
+	}
+	return domain.User{
+		Username:     username,
+		Email:        username + "@" + emailDomains[rng.Intn(len(emailDomains))],
+		PasswordHash: passwordHash,
+		Role:         role,
+		CreatedAt:    created,
+		UpdatedAt:    created,
+	}
+}
+
+func fakePet(rng *rand.Rand, ownerSince time.Time) domain.Pet {
+	sp := species[rng.Intn(len(species))]
+	// log-uniform between the species' bounds: many cheap pets, a few expensive ones
+	price := math.Exp(math.Log(sp.minPrice) + rng.Float64()*(math.Log(sp.maxPrice)-math.Log(sp.minPrice)))
+	price = math.Round(price*100) / 100
+	created := ownerSince.Add(time.Duration(rng.Int63n(int64(epoch.Sub(ownerSince)) + 1)))
+	updated := created
+	if rng.Intn(4) == 0 {
+		updated = created.Add(time.Duration(rng.Int63n(int64(epoch.Sub(created)) + 1)))
+	}
+	pet := domain.Pet{
+		Name:      petNames[rng.Intn(len(petNames))],
+		Species:   sp.name,
+		Price:     price,
+		CreatedAt: created,
+		UpdatedAt: updated,
+	}
+	// most pets get a profile; the rest show what unknown attributes look like
+	if rng.Intn(5) > 0 {
+		born := created.AddDate(0, 0, -rng.Intn(sp.maxAge*365+1))
+		pet.DateOfBirth = time.Date(born.Year(), born.Month(), born.Day(), 0, 0, 0, 0, time.UTC)
+		pet.Sex = []string{"male", "female"}[rng.Intn(2)]
+		pet.Color = colors[rng.Intn(len(colors))]
+		weight := sp.minWeight + rng.Float64()*(sp.maxWeight-sp.minWeight)
+		pet.Weight, pet.WeightUnit = math.Round(weight*1000)/1000, "kg"
+		neutered := rng.Intn(2) == 0
+		pet.Neutered = &neutered
+	}
+	return pet
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"Go-PetStoreApp/audit"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/logx"
+	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/repository"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// recordAudit appends an entry for a change to the audit log, in the transaction that makes
+// the change so both commit or neither does. before and after are snapshots of the entity,
+// e.g. its response, or nil when it did not exist; only the fields that differ are kept.
+func recordAudit(ctx context.Context, tx *sql.Tx, repo repository.AuditRepository, action, entity string, entityID int, before, after any) {
+	oldJSON, newJSON, err := audit.Diff(before, after)
+	helper.PanicIfError(err)
+	source := audit.FromContext(ctx)
+	repo.Create(ctx, tx, domain.AuditEntry{
+		OccurredAt: time.Now(),
+		ActorID:    source.ActorID,
+		Action:     action,
+		Entity:     entity,
+		EntityID:   entityID,
+		Before:     oldJSON,
+		After:      newJSON,
+		RequestID:  logx.RequestID(ctx),
+		IP:         source.IP,
+	})
+}
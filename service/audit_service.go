@@ -0,0 +1,14 @@
+package service
+
+import (
+	"Go-PetStoreApp/model/web"
+	"context"
+)
+
+// AuditService reads the audit log; entries are written by the services that make the changes.
+type AuditService interface {
+	FindAll(ctx context.Context, req web.AuditListRequest) (web.AuditPageResponse, error)
+	// PetHistory returns the changes to a pet for its owner, without client IPs. It covers
+	// the pet's whole life, so it also works for a deleted pet.
+	PetHistory(ctx context.Context, petID int, userID int, page, limit int) (web.AuditPageResponse, error)
+}
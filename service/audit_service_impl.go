@@ -0,0 +1,104 @@
+package service
+
+import (
+	"Go-PetStoreApp/audit"
+	"Go-PetStoreApp/errorsx"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/model/web"
+	"Go-PetStoreApp/repository"
+	"Go-PetStoreApp/tracing"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/go-playground/validator"
+)
+
+type AuditServiceImpl struct {
+	AuditRepository repository.AuditRepository
+	PetRepository   repository.PetRepository
+	DB              *sql.DB
+	Validate        *validator.Validate
+}
+
+func NewAuditService(audits repository.AuditRepository, pets repository.PetRepository, db *sql.DB, validate *validator.Validate) AuditService {
+	return &AuditServiceImpl{AuditRepository: audits, PetRepository: pets, DB: db, Validate: validate}
+}
+
+func (s *AuditServiceImpl) FindAll(ctx context.Context, req web.AuditListRequest) (_ web.AuditPageResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditService.FindAll")
+	defer span.End()
+
+	if err := s.Validate.Struct(req); err != nil {
+		return web.AuditPageResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+	if !req.From.IsZero() && !req.Before.IsZero() && !req.From.Before(req.Before) {
+		return web.AuditPageResponse{}, fmt.Errorf("%w: from must be before to", errorsx.ErrValidation)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.AuditPageResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	entries, total := s.AuditRepository.FindPage(ctx, tx, repository.AuditQuery{
+		ActorID:   req.ActorID,
+		Action:    req.Action,
+		Entity:    req.Entity,
+		EntityID:  req.EntityID,
+		RequestID: req.RequestID,
+		From:      req.From,
+		Before:    req.Before,
+		Limit:     req.Limit,
+		Offset:    (req.Page - 1) * req.Limit,
+	})
+	return auditPage(entries, req.Page, req.Limit, total, true), nil
+}
+
+func (s *AuditServiceImpl) PetHistory(ctx context.Context, petID int, userID int, page, limit int) (_ web.AuditPageResponse, err error) {
+	ctx, span := tracing.Start(ctx, "AuditService.PetHistory")
+	defer span.End()
+
+	if page < 1 || limit < 1 || limit > web.MaxAuditPageLimit {
+		return web.AuditPageResponse{}, fmt.Errorf("%w: page must be positive and limit between 1 and %d", errorsx.ErrValidation, web.MaxAuditPageLimit)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.AuditPageResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	pet, err := s.PetRepository.FindById(ctx, tx, petID)
+	if err != nil {
+		// the owner can still look back at a pet they deleted
+		if pet, err = s.PetRepository.FindDeletedById(ctx, tx, petID); err != nil {
+			return web.AuditPageResponse{}, fmt.Errorf("%w: pet not found", errorsx.ErrNotFound)
+		}
+	}
+	if pet.CreatedBy != userID {
+		return web.AuditPageResponse{}, fmt.Errorf("%w: not owner", errorsx.ErrUnauthorized)
+	}
+
+	entries, total := s.AuditRepository.FindPage(ctx, tx, repository.AuditQuery{
+		Entity:   audit.EntityPet,
+		EntityID: petID,
+		Limit:    limit,
+		Offset:   (page - 1) * limit,
+	})
+	return auditPage(entries, page, limit, total, false), nil
+}
+
+// auditPage maps a page of entries; withIP is for admins only.
+func auditPage(entries []domain.AuditEntry, page, limit, total int, withIP bool) web.AuditPageResponse {
+	resp := web.AuditPageResponse{Items: make([]web.AuditEntryResponse, 0, len(entries)), Page: page, Limit: limit, Total: total}
+	for _, e := range entries {
+		if !withIP {
+			e.IP = ""
+		}
+		resp.Items = append(resp.Items, helper.ToAuditEntryResponse(e))
+	}
+	return resp
+}
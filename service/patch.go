@@ -0,0 +1,74 @@
+package service
+
+import (
+	"Go-PetStoreApp/errorsx"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/jsonpatch"
+	"Go-PetStoreApp/model/web"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+	"strings"
+)
+
+// applyPatch applies the patch of req to the JSON document of current, usually an update
+// request built from the entity, and decodes the result into patched. Members the document
+// does not have are rejected, so a typo fails instead of being ignored; the caller still
+// has to validate patched.
+func applyPatch(current any, req web.PatchRequest, patched any) error {
+	doc, err := json.Marshal(patchDocument(reflect.ValueOf(current), map[string]json.RawMessage{}))
+	helper.PanicIfError(err)
+
+	mediaType, _, _ := mime.ParseMediaType(req.ContentType)
+	var result []byte
+	switch mediaType {
+	case jsonpatch.MergePatchType:
+		result, err = jsonpatch.MergePatch(doc, req.Patch)
+	case jsonpatch.JSONPatchType:
+		result, err = jsonpatch.Apply(doc, req.Patch)
+	default:
+		return fmt.Errorf("%w: %q, use %s or %s", errorsx.ErrUnsupported, req.ContentType, jsonpatch.MergePatchType, jsonpatch.JSONPatchType)
+	}
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return fmt.Errorf("%w: %v", errorsx.ErrConflict, err)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(result))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(patched); err != nil {
+		return fmt.Errorf("%w: patched document: %v", errorsx.ErrValidation, err)
+	}
+	return nil
+}
+
+// patchDocument collects the JSON members of struct v into doc, ignoring omitempty: a JSON
+// Patch can only replace or test members that exist, so unset fields are there with their
+// zero value ("", 0 or null). Embedded structs are flattened, as encoding/json does.
+func patchDocument(v reflect.Value, doc map[string]json.RawMessage) map[string]json.RawMessage {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			patchDocument(v.Field(i), doc)
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		value, err := json.Marshal(v.Field(i).Interface())
+		helper.PanicIfError(err)
+		doc[name] = value
+	}
+	return doc
+}
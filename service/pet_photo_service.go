@@ -0,0 +1,24 @@
+package service
+
+import (
+	"Go-PetStoreApp/model/web"
+	"context"
+)
+
+// PetPhotoService manages the photos of a pet; only the pet's owner may change them.
+// Photos are part of the pet, so every change increments the pet's version (its ETag).
+type PetPhotoService interface {
+	Upload(ctx context.Context, req web.PetPhotoUpload, userID int) (web.PetPhotoResponse, error)
+	FindByPet(ctx context.Context, petID int, userID int) ([]web.PetPhotoResponse, error)
+	Reorder(ctx context.Context, req web.PetPhotoOrderRequest, userID int) ([]web.PetPhotoResponse, error)
+	SetPrimary(ctx context.Context, petID, photoID int, userID int) ([]web.PetPhotoResponse, error)
+	Delete(ctx context.Context, petID, photoID int, userID int) error
+}
+
+// PhotoLimits bound uploads: MaxBytes per file, MaxPixels per decoded image (against
+// decompression bombs) and MaxPerPet photos.
+type PhotoLimits struct {
+	MaxBytes  int64
+	MaxPixels int
+	MaxPerPet int
+}
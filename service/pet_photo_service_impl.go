@@ -0,0 +1,325 @@
+package service
+
+import (
+	"Go-PetStoreApp/audit"
+	"Go-PetStoreApp/blob"
+	"Go-PetStoreApp/errorsx"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/imagex"
+	"Go-PetStoreApp/logx"
+	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/model/web"
+	"Go-PetStoreApp/repository"
+	"Go-PetStoreApp/tracing"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+// Longest side, in pixels, of the resized copies of each photo.
+const (
+	mediumPhotoSize    = 1024
+	thumbnailPhotoSize = 256
+)
+
+type PetPhotoServiceImpl struct {
+	PetRepository      repository.PetRepository
+	PetPhotoRepository repository.PetPhotoRepository
+	AuditRepository    repository.AuditRepository
+	BlobStore          blob.Store
+	DB                 *sql.DB
+	Validate           *validator.Validate
+	Limits             PhotoLimits
+}
+
+func NewPetPhotoService(pets repository.PetRepository, photos repository.PetPhotoRepository, audits repository.AuditRepository, store blob.Store, db *sql.DB, validate *validator.Validate, limits PhotoLimits) PetPhotoService {
+	return &PetPhotoServiceImpl{PetRepository: pets, PetPhotoRepository: photos, AuditRepository: audits, BlobStore: store, DB: db, Validate: validate, Limits: limits}
+}
+
+// photoIDs is the pet's side of a photo change in the audit log: its photos, in order.
+func photoIDs(photos []domain.PetPhoto) map[string][]int {
+	ids := make([]int, 0, len(photos))
+	for _, p := range photos {
+		ids = append(ids, p.ID)
+	}
+	return map[string][]int{"photo_ids": ids}
+}
+
+// ownPet loads a pet and checks that userID owns it.
+func (s *PetPhotoServiceImpl) ownPet(ctx context.Context, tx *sql.Tx, petID, userID int) error {
+	pet, err := s.PetRepository.FindById(ctx, tx, petID)
+	if err != nil {
+		return fmt.Errorf("%w: pet not found", errorsx.ErrNotFound)
+	}
+	if pet.CreatedBy != userID {
+		return fmt.Errorf("%w: not owner", errorsx.ErrUnauthorized)
+	}
+	return nil
+}
+
+func (s *PetPhotoServiceImpl) responses(photos []domain.PetPhoto) []web.PetPhotoResponse {
+	res := make([]web.PetPhotoResponse, 0, len(photos))
+	for _, p := range photos {
+		res = append(res, helper.ToPetPhotoResponse(p, s.BlobStore.URL))
+	}
+	return res
+}
+
+// deleteBlobs removes stored files on a best-effort basis; a failure leaves an orphan
+// file behind but nothing that references it.
+func (s *PetPhotoServiceImpl) deleteBlobs(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := s.BlobStore.Delete(ctx, key); err != nil {
+			logx.FromContext(ctx).Warn("deleting photo file", "key", key, "error", err)
+		}
+	}
+}
+
+// Upload checks the pet before doing any image work, stores the three sizes and then
+// records the photo. The files are removed again if recording fails.
+func (s *PetPhotoServiceImpl) Upload(ctx context.Context, req web.PetPhotoUpload, userID int) (web.PetPhotoResponse, error) {
+	ctx, span := tracing.Start(ctx, "PetPhotoService.Upload")
+	defer span.End()
+
+	if len(req.Data) == 0 {
+		return web.PetPhotoResponse{}, fmt.Errorf("%w: the photo is empty", errorsx.ErrValidation)
+	}
+	if int64(len(req.Data)) > s.Limits.MaxBytes {
+		return web.PetPhotoResponse{}, fmt.Errorf("%w: photos may be at most %d bytes", errorsx.ErrTooLarge, s.Limits.MaxBytes)
+	}
+	if err := s.checkRoom(ctx, req.PetId, userID); err != nil {
+		return web.PetPhotoResponse{}, err
+	}
+
+	img, err := imagex.Decode(req.Data, s.Limits.MaxPixels)
+	switch {
+	case errors.Is(err, imagex.ErrUnsupported):
+		return web.PetPhotoResponse{}, fmt.Errorf("%w: %v; upload a JPEG, PNG or GIF", errorsx.ErrUnsupported, err)
+	case errors.Is(err, imagex.ErrTooLarge):
+		return web.PetPhotoResponse{}, fmt.Errorf("%w: %v", errorsx.ErrTooLarge, err)
+	case err != nil:
+		return web.PetPhotoResponse{}, err
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return web.PetPhotoResponse{}, err
+	}
+	prefix := "pets/" + strconv.Itoa(req.PetId) + "/" + hex.EncodeToString(id) + "/"
+	photo := domain.PetPhoto{
+		PetID:     req.PetId,
+		Primary:   req.Primary,
+		Width:     img.Bounds().Dx(),
+		Height:    img.Bounds().Dy(),
+		CreatedAt: time.Now(),
+	}
+	var stored []string
+	for _, variant := range []struct {
+		name string
+		size int
+		key  *string
+	}{
+		{"original", 0, &photo.OriginalKey},
+		{"medium", mediumPhotoSize, &photo.MediumKey},
+		{"thumbnail", thumbnailPhotoSize, &photo.ThumbnailKey},
+	} {
+		sized := img
+		if variant.size > 0 {
+			sized = imagex.Fit(img, variant.size)
+		}
+		data, contentType, ext, err := imagex.Encode(sized)
+		if err != nil {
+			s.deleteBlobs(ctx, stored...)
+			return web.PetPhotoResponse{}, err
+		}
+		*variant.key = prefix + variant.name + ext
+		if err := s.BlobStore.Put(ctx, *variant.key, data, contentType); err != nil {
+			s.deleteBlobs(ctx, stored...)
+			return web.PetPhotoResponse{}, fmt.Errorf("storing photo: %w", err)
+		}
+		stored = append(stored, *variant.key)
+		if variant.size == 0 {
+			photo.ContentType, photo.SizeBytes = contentType, len(data)
+		}
+	}
+
+	recorded := false
+	defer func() {
+		if !recorded {
+			s.deleteBlobs(context.WithoutCancel(ctx), stored...)
+		}
+	}()
+	photo, err = s.record(ctx, photo, userID)
+	if err != nil {
+		return web.PetPhotoResponse{}, err
+	}
+	recorded = true
+	logx.FromContext(ctx).Info("pet photo uploaded", "pet_id", photo.PetID, "photo_id", photo.ID, "bytes", len(req.Data))
+	return helper.ToPetPhotoResponse(photo, s.BlobStore.URL), nil
+}
+
+// checkRoom fails if the pet is not the user's or already has the maximum of photos.
+func (s *PetPhotoServiceImpl) checkRoom(ctx context.Context, petID, userID int) (err error) {
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	if err := s.ownPet(ctx, tx, petID, userID); err != nil {
+		return err
+	}
+	if n := len(s.PetPhotoRepository.FindByPet(ctx, tx, petID)); n >= s.Limits.MaxPerPet {
+		return fmt.Errorf("%w: a pet can have at most %d photos", errorsx.ErrConflict, s.Limits.MaxPerPet)
+	}
+	return nil
+}
+
+// record saves a stored photo, repeating the checks of checkRoom under the pet's lock.
+func (s *PetPhotoServiceImpl) record(ctx context.Context, photo domain.PetPhoto, userID int) (_ domain.PetPhoto, err error) {
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return domain.PetPhoto{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	s.PetPhotoRepository.Lock(ctx, tx, photo.PetID)
+	if err := s.ownPet(ctx, tx, photo.PetID, userID); err != nil {
+		return domain.PetPhoto{}, err
+	}
+	existing := s.PetPhotoRepository.FindByPet(ctx, tx, photo.PetID)
+	if len(existing) >= s.Limits.MaxPerPet {
+		return domain.PetPhoto{}, fmt.Errorf("%w: a pet can have at most %d photos", errorsx.ErrConflict, s.Limits.MaxPerPet)
+	}
+	primary := photo.Primary || len(existing) == 0
+	photo.Primary = false
+	photo = s.PetPhotoRepository.Create(ctx, tx, photo)
+	if primary {
+		s.PetPhotoRepository.SetPrimary(ctx, tx, photo.PetID, photo.ID)
+		photo.Primary = true
+	}
+	s.PetRepository.Touch(ctx, tx, photo.PetID)
+	recordAudit(ctx, tx, s.AuditRepository, audit.ActionUpdate, audit.EntityPet, photo.PetID,
+		photoIDs(existing), photoIDs(s.PetPhotoRepository.FindByPet(ctx, tx, photo.PetID)))
+	return photo, nil
+}
+
+func (s *PetPhotoServiceImpl) FindByPet(ctx context.Context, petID int, userID int) (_ []web.PetPhotoResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PetPhotoService.FindByPet")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	if err := s.ownPet(ctx, tx, petID, userID); err != nil {
+		return nil, err
+	}
+	return s.responses(s.PetPhotoRepository.FindByPet(ctx, tx, petID)), nil
+}
+
+func (s *PetPhotoServiceImpl) Reorder(ctx context.Context, req web.PetPhotoOrderRequest, userID int) (_ []web.PetPhotoResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PetPhotoService.Reorder")
+	defer span.End()
+
+	if err := s.Validate.Struct(req); err != nil {
+		return nil, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	s.PetPhotoRepository.Lock(ctx, tx, req.PetId)
+	if err := s.ownPet(ctx, tx, req.PetId, userID); err != nil {
+		return nil, err
+	}
+	var current []int
+	for _, p := range s.PetPhotoRepository.FindByPet(ctx, tx, req.PetId) {
+		current = append(current, p.ID)
+	}
+	given := slices.Clone(req.PhotoIds)
+	slices.Sort(current)
+	slices.Sort(given)
+	if !slices.Equal(current, given) {
+		return nil, fmt.Errorf("%w: photo_ids must list each of the pet's %d photos once", errorsx.ErrValidation, len(current))
+	}
+	s.PetPhotoRepository.SetOrder(ctx, tx, req.PetId, req.PhotoIds)
+	s.PetRepository.Touch(ctx, tx, req.PetId)
+	return s.responses(s.PetPhotoRepository.FindByPet(ctx, tx, req.PetId)), nil
+}
+
+func (s *PetPhotoServiceImpl) SetPrimary(ctx context.Context, petID, photoID int, userID int) (_ []web.PetPhotoResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PetPhotoService.SetPrimary")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	s.PetPhotoRepository.Lock(ctx, tx, petID)
+	if err := s.ownPet(ctx, tx, petID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.PetPhotoRepository.FindById(ctx, tx, petID, photoID); err != nil {
+		return nil, fmt.Errorf("%w: photo not found", errorsx.ErrNotFound)
+	}
+	s.PetPhotoRepository.SetPrimary(ctx, tx, petID, photoID)
+	s.PetRepository.Touch(ctx, tx, petID)
+	return s.responses(s.PetPhotoRepository.FindByPet(ctx, tx, petID)), nil
+}
+
+// Delete removes the photo's files once the row is gone. If it was the primary photo, the
+// first remaining one takes over.
+func (s *PetPhotoServiceImpl) Delete(ctx context.Context, petID, photoID int, userID int) error {
+	ctx, span := tracing.Start(ctx, "PetPhotoService.Delete")
+	defer span.End()
+
+	photo, err := s.deleteRow(ctx, petID, photoID, userID)
+	if err != nil {
+		return err
+	}
+	s.deleteBlobs(ctx, photo.OriginalKey, photo.MediumKey, photo.ThumbnailKey)
+	logx.FromContext(ctx).Info("pet photo deleted", "pet_id", petID, "photo_id", photoID)
+	return nil
+}
+
+func (s *PetPhotoServiceImpl) deleteRow(ctx context.Context, petID, photoID int, userID int) (_ domain.PetPhoto, err error) {
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return domain.PetPhoto{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	s.PetPhotoRepository.Lock(ctx, tx, petID)
+	if err := s.ownPet(ctx, tx, petID, userID); err != nil {
+		return domain.PetPhoto{}, err
+	}
+	photo, err := s.PetPhotoRepository.FindById(ctx, tx, petID, photoID)
+	if err != nil {
+		return domain.PetPhoto{}, fmt.Errorf("%w: photo not found", errorsx.ErrNotFound)
+	}
+	before := s.PetPhotoRepository.FindByPet(ctx, tx, petID)
+	s.PetPhotoRepository.Delete(ctx, tx, photo)
+	rest := s.PetPhotoRepository.FindByPet(ctx, tx, petID)
+	if photo.Primary && len(rest) > 0 {
+		s.PetPhotoRepository.SetPrimary(ctx, tx, petID, rest[0].ID)
+	}
+	s.PetRepository.Touch(ctx, tx, petID)
+	recordAudit(ctx, tx, s.AuditRepository, audit.ActionUpdate, audit.EntityPet, petID, photoIDs(before), photoIDs(rest))
+	return photo, nil
+}
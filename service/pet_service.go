@@ -7,8 +7,26 @@ import (
 
 type PetService interface {
 	Create(ctx context.Context, req web.PetCreateRequest, userID int) (web.PetResponse, error)
-	FindAllByUser(ctx context.Context, userID, page, limit int, species string) ([]web.PetResponse, int, error)
+	FindAllByUser(ctx context.Context, req web.PetListRequest) (PetPage, error)
+	Search(ctx context.Context, req web.PetSearchRequest) (web.PetSearchResponse, error)
+	// Reindex feeds every pet to the search engine, e.g. to fill the in-memory index at startup.
+	Reindex(ctx context.Context) (int, error)
 	FindById(ctx context.Context, petID int, userID int) (web.PetResponse, error)
 	Update(ctx context.Context, req web.PetUpdateRequest, userID int) (web.PetResponse, error)
-	Delete(ctx context.Context, petID int, userID int) error
-}
\ No newline at end of file
+	// Patch changes only the fields a merge patch or JSON Patch mentions; see web.PatchRequest.
+	Patch(ctx context.Context, req web.PatchRequest, userID int) (web.PetResponse, error)
+	// Delete soft-deletes a pet, unless ifMatch (an If-Match header) names another version;
+	// Restore (admin) undoes it until the pet is purged.
+	Delete(ctx context.Context, petID int, userID int, ifMatch string) error
+	Restore(ctx context.Context, petID int) (web.PetResponse, error)
+	ReassignOwner(ctx context.Context, fromUserID, toUserID int) (int, error)
+}
+
+// PetPage is one page of a listing. Next and Prev are nil at either end; Total is nil when
+// the count was skipped.
+type PetPage struct {
+	Items []web.PetResponse
+	Total *int
+	Next  *web.PetCursor
+	Prev  *web.PetCursor
+}
@@ -1,69 +1,367 @@
 package service
 
 import (
+	"Go-PetStoreApp/audit"
+	"Go-PetStoreApp/blob"
 	"Go-PetStoreApp/errorsx"
 	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/logx"
+	"Go-PetStoreApp/metrics"
 	"Go-PetStoreApp/model/domain"
 	"Go-PetStoreApp/model/web"
 	"Go-PetStoreApp/repository"
+	"Go-PetStoreApp/search"
+	"Go-PetStoreApp/tracing"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/go-playground/validator"
 )
 
 type PetServiceImpl struct {
-	PetRepository repository.PetRepository
-	DB            *sql.DB
+	PetRepository      repository.PetRepository
+	UserRepository     repository.UserRepository
+	TaxonomyRepository repository.TaxonomyRepository
+	PetPhotoRepository repository.PetPhotoRepository
+	AuditRepository    repository.AuditRepository
+	DB                 *sql.DB
+	Validate           *validator.Validate
+	SearchEngine       search.Engine
+	BlobStore          blob.Store
+}
+
+func NewPetService(repo repository.PetRepository, users repository.UserRepository, taxonomy repository.TaxonomyRepository, photos repository.PetPhotoRepository, audits repository.AuditRepository, db *sql.DB, validate *validator.Validate, engine search.Engine, store blob.Store) PetService {
+	return &PetServiceImpl{PetRepository: repo, UserRepository: users, TaxonomyRepository: taxonomy, PetPhotoRepository: photos, AuditRepository: audits, DB: db, Validate: validate, SearchEngine: engine, BlobStore: store}
 }
 
-func NewPetService(repo repository.PetRepository, db *sql.DB) PetService {
-	return &PetServiceImpl{PetRepository: repo, DB: db}
+// withPhotos fills in the photos of pet responses, loading them in one query.
+func (s *PetServiceImpl) withPhotos(ctx context.Context, tx *sql.Tx, pets []web.PetResponse) {
+	ids := make([]int, len(pets))
+	for i, p := range pets {
+		ids[i] = p.Id
+	}
+	photos := s.PetPhotoRepository.FindByPets(ctx, tx, ids)
+	for i := range pets {
+		for _, photo := range photos[pets[i].Id] {
+			pets[i].Photos = append(pets[i].Photos, helper.ToPetPhotoResponse(photo, s.BlobStore.URL))
+		}
+	}
+}
+
+// resolveTaxonomy maps species and breed names, in any case, to their catalog spelling and
+// rejects names that are not in the catalog.
+func (s *PetServiceImpl) resolveTaxonomy(ctx context.Context, tx *sql.Tx, species, breed string) (string, string, error) {
+	sp, err := s.TaxonomyRepository.FindSpeciesByName(ctx, tx, catalogName(species))
+	if err != nil {
+		return "", "", fmt.Errorf("%w: unknown species %q", errorsx.ErrValidation, species)
+	}
+	if breed = catalogName(breed); breed == "" {
+		return sp.Name, "", nil
+	}
+	b, err := s.TaxonomyRepository.FindBreedByName(ctx, tx, sp.Name, breed)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: unknown %s breed %q", errorsx.ErrValidation, sp.Name, breed)
+	}
+	return sp.Name, b.Name, nil
+}
+
+// normalizeMicrochip drops the spaces and dashes chip IDs are often written with.
+func normalizeMicrochip(chip string) string {
+	return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(chip))
+}
+
+// applyProfile sets the optional attributes of pet from a validated request, rejecting a
+// birth date in the future and a microchip registered to another pet.
+func (s *PetServiceImpl) applyProfile(ctx context.Context, tx *sql.Tx, pet *domain.Pet, req web.PetProfile) error {
+	pet.DateOfBirth = time.Time{}
+	if req.DateOfBirth != "" {
+		dob, err := time.Parse(time.DateOnly, req.DateOfBirth)
+		if err != nil {
+			return fmt.Errorf("%w: date_of_birth must be a date (2006-01-02)", errorsx.ErrValidation)
+		}
+		if dob.After(time.Now().UTC()) || dob.Year() < 1900 {
+			return fmt.Errorf("%w: date_of_birth %s is out of range", errorsx.ErrValidation, req.DateOfBirth)
+		}
+		pet.DateOfBirth = dob
+	}
+	pet.Weight, pet.WeightUnit = req.Weight, ""
+	if req.Weight > 0 {
+		pet.WeightUnit = req.WeightUnit
+		if pet.WeightUnit == "" {
+			pet.WeightUnit = "kg"
+		}
+	}
+	if req.Microchip != "" {
+		if other, err := s.PetRepository.FindByMicrochip(ctx, tx, req.Microchip); err == nil && other.ID != pet.ID {
+			return fmt.Errorf("%w: microchip %s is already registered to another pet", errorsx.ErrConflict, req.Microchip)
+		}
+	}
+	pet.Sex = req.Sex
+	pet.Color = strings.TrimSpace(req.Color)
+	pet.Neutered = req.Neutered
+	pet.Microchip = req.Microchip
+	pet.Description = strings.TrimSpace(req.Description)
+	return nil
 }
 
-func (s *PetServiceImpl) Create(ctx context.Context, req web.PetCreateRequest, userID int) (web.PetResponse, error) {
+func (s *PetServiceImpl) Create(ctx context.Context, req web.PetCreateRequest, userID int) (_ web.PetResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PetService.Create")
+	defer span.End()
+
+	req.Microchip = normalizeMicrochip(req.Microchip)
+	if err := s.Validate.Struct(req); err != nil {
+		return web.PetResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.PetResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	species, breed, err := s.resolveTaxonomy(ctx, tx, req.Species, req.Breed)
+	if err != nil {
+		return web.PetResponse{}, err
+	}
 	pet := domain.Pet{
 		Name:      req.Name,
-		Species:   req.Species,
+		Species:   species,
+		Breed:     breed,
 		Price:     req.Price,
 		CreatedBy: userID,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-
-	tx, err := s.DB.Begin()
-	if err != nil {
+	if err := s.applyProfile(ctx, tx, &pet, req.PetProfile); err != nil {
 		return web.PetResponse{}, err
 	}
-	defer helper.CommitOrRollback(tx)
 
 	created := s.PetRepository.Create(ctx, tx, pet)
+	recordAudit(ctx, tx, s.AuditRepository, audit.ActionCreate, audit.EntityPet, created.ID, nil, helper.ToPetResponse(created))
+	s.SearchEngine.Index(created)
+	metrics.PetsCreated.Inc()
+	logx.FromContext(ctx).Info("pet created", "pet_id", created.ID)
 	return helper.ToPetResponse(created), nil
 }
 
-func (s *PetServiceImpl) FindAllByUser(ctx context.Context, userID, page, limit int, species string) ([]web.PetResponse, int, error) {
-	tx, err := s.DB.Begin()
+// maxSpeciesFilter bounds the IN list of a species filter.
+const maxSpeciesFilter = 20
+
+func (s *PetServiceImpl) FindAllByUser(ctx context.Context, req web.PetListRequest) (_ PetPage, err error) {
+	ctx, span := tracing.Start(ctx, "PetService.FindAllByUser")
+	defer span.End()
+
+	if req.Limit <= 0 || req.Limit > web.MaxPetPageLimit {
+		return PetPage{}, fmt.Errorf("%w: limit must be between 1 and %d", errorsx.ErrValidation, web.MaxPetPageLimit)
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	sorts, err := repository.ParsePetSort(req.Sort)
 	if err != nil {
-		return nil, 0, err
+		return PetPage{}, fmt.Errorf("%w: sort: %v", errorsx.ErrValidation, err)
+	}
+	sortSpec := repository.FormatPetSort(sorts)
+	if req.Cursor != nil && req.Cursor.Sort != sortSpec {
+		return PetPage{}, fmt.Errorf("%w: cursor was issued for a different sort", errorsx.ErrValidation)
+	}
+	if len(req.Species) > maxSpeciesFilter {
+		return PetPage{}, fmt.Errorf("%w: at most %d species can be filtered on", errorsx.ErrValidation, maxSpeciesFilter)
+	}
+	if req.MinPrice != nil && req.MaxPrice != nil && *req.MinPrice > *req.MaxPrice {
+		return PetPage{}, fmt.Errorf("%w: min_price is greater than max_price", errorsx.ErrValidation)
+	}
+	if !req.CreatedFrom.IsZero() && !req.CreatedBefore.IsZero() && !req.CreatedFrom.Before(req.CreatedBefore) {
+		return PetPage{}, fmt.Errorf("%w: created_from must be before created_to", errorsx.ErrValidation)
+	}
+	if !req.UpdatedFrom.IsZero() && !req.UpdatedBefore.IsZero() && !req.UpdatedFrom.Before(req.UpdatedBefore) {
+		return PetPage{}, fmt.Errorf("%w: updated_from must be before updated_to", errorsx.ErrValidation)
+	}
+	if req.Sex != "" && req.Sex != "male" && req.Sex != "female" {
+		return PetPage{}, fmt.Errorf("%w: sex must be male or female", errorsx.ErrValidation)
+	}
+	if req.MinWeightKg != nil && req.MaxWeightKg != nil && *req.MinWeightKg > *req.MaxWeightKg {
+		return PetPage{}, fmt.Errorf("%w: min_weight is greater than max_weight", errorsx.ErrValidation)
+	}
+	if req.MinAge != nil && req.MaxAge != nil && *req.MinAge > *req.MaxAge {
+		return PetPage{}, fmt.Errorf("%w: min_age is greater than max_age", errorsx.ErrValidation)
+	}
+	bornFrom, bornBefore := bornRange(req, time.Now().UTC())
+	if !bornFrom.IsZero() && !bornBefore.IsZero() && !bornFrom.Before(bornBefore) {
+		return PetPage{}, fmt.Errorf("%w: the date of birth and age filters match no date", errorsx.ErrValidation)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return PetPage{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	// one extra row tells whether another page follows in the direction of travel
+	query := repository.PetQuery{
+		OwnerID:       req.OwnerID,
+		Species:       req.Species,
+		Name:          req.Name,
+		MinPrice:      req.MinPrice,
+		MaxPrice:      req.MaxPrice,
+		CreatedFrom:   req.CreatedFrom,
+		CreatedBefore: req.CreatedBefore,
+		UpdatedFrom:   req.UpdatedFrom,
+		UpdatedBefore: req.UpdatedBefore,
+		Sex:           req.Sex,
+		Color:         req.Color,
+		Neutered:      req.Neutered,
+		Microchip:     normalizeMicrochip(req.Microchip),
+		BornFrom:      bornFrom,
+		BornBefore:    bornBefore,
+		MinWeightKg:   req.MinWeightKg,
+		MaxWeightKg:   req.MaxWeightKg,
+		Deleted:       req.Deleted,
+		Sort:          sorts,
+		Limit:         req.Limit + 1,
+		CountTotal:    !req.SkipTotal,
+	}
+	backward := false
+	switch {
+	case req.Cursor != nil && req.Cursor.Before:
+		query.Before, backward = req.Cursor.Key, true
+	case req.Cursor != nil:
+		query.After = req.Cursor.Key
+	default:
+		query.Offset = (req.Page - 1) * req.Limit
 	}
-	defer helper.CommitOrRollback(tx)
+	pets, total := s.PetRepository.FindPage(ctx, tx, query)
 
-	offset := (page - 1) * limit
-	pets, total := s.PetRepository.FindAllWithFilterByUser(ctx, tx, userID, limit, offset, species)
+	more := len(pets) > req.Limit
+	if more {
+		if backward {
+			pets = pets[1:]
+		} else {
+			pets = pets[:req.Limit]
+		}
+	}
 
-	res := make([]web.PetResponse, 0, len(pets))
+	page := PetPage{Items: make([]web.PetResponse, 0, len(pets))}
 	for _, p := range pets {
-		res = append(res, helper.ToPetResponse(p))
+		page.Items = append(page.Items, helper.ToPetResponse(p))
+	}
+	s.withPhotos(ctx, tx, page.Items)
+	if total >= 0 {
+		page.Total = &total
+	}
+	if len(pets) > 0 {
+		first, last := pets[0], pets[len(pets)-1]
+		// coming back from a later page there is always one after; from an earlier page or
+		// offset > 0 there is always one before
+		if more && !backward || backward {
+			page.Next = &web.PetCursor{Sort: sortSpec, Key: repository.PetKey(last, sorts)}
+		}
+		if more && backward || !backward && (req.Cursor != nil || query.Offset > 0) {
+			page.Prev = &web.PetCursor{Sort: sortSpec, Key: repository.PetKey(first, sorts), Before: true}
+		}
 	}
-	return res, total, nil
+	return page, nil
 }
 
-func (s *PetServiceImpl) FindById(ctx context.Context, petID int, userID int) (web.PetResponse, error) {
-	tx, err := s.DB.Begin()
+// bornRange combines the date of birth and age filters of a listing into one range of
+// birth dates, [from, before). An age of n years covers births from n+1 years ago,
+// exclusive, to n years ago, inclusive.
+func bornRange(req web.PetListRequest, now time.Time) (from, before time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	from, before = req.BornFrom, req.BornBefore
+	if req.MaxAge != nil {
+		if t := today.AddDate(-*req.MaxAge-1, 0, 1); t.After(from) {
+			from = t
+		}
+	}
+	if req.MinAge != nil {
+		if t := today.AddDate(-*req.MinAge, 0, 1); before.IsZero() || t.Before(before) {
+			before = t
+		}
+	}
+	return from, before
+}
+
+func (s *PetServiceImpl) Search(ctx context.Context, req web.PetSearchRequest) (_ web.PetSearchResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PetService.Search")
+	defer span.End()
+
+	if err := s.Validate.Struct(req); err != nil {
+		return web.PetSearchResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+	if len(search.Terms(req.Query)) == 0 {
+		return web.PetSearchResponse{}, fmt.Errorf("%w: q must contain a word", errorsx.ErrValidation)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.PetSearchResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	hits, total, err := s.SearchEngine.Search(ctx, tx, search.Query{
+		Text:    req.Query,
+		OwnerID: req.OwnerID,
+		Limit:   req.Limit,
+		Offset:  (req.Page - 1) * req.Limit,
+	})
+	if err != nil {
+		return web.PetSearchResponse{}, err
+	}
+	pets := make([]web.PetResponse, len(hits))
+	for i, h := range hits {
+		pets[i] = helper.ToPetResponse(h.Pet)
+	}
+	s.withPhotos(ctx, tx, pets)
+	resp := web.PetSearchResponse{Items: make([]web.PetSearchHit, 0, len(hits)), Page: req.Page, Limit: req.Limit, Total: total}
+	for i, h := range hits {
+		resp.Items = append(resp.Items, web.PetSearchHit{PetResponse: pets[i], Rank: h.Rank, Highlights: h.Highlights})
+	}
+	return resp, nil
+}
+
+func (s *PetServiceImpl) Reindex(ctx context.Context) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "PetService.Reindex")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return 0, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+	return s.reindex(ctx, tx, 0), nil
+}
+
+// reindex feeds the pets of ownerID (all pets for 0) to the search engine, in keyset pages.
+func (s *PetServiceImpl) reindex(ctx context.Context, tx *sql.Tx, ownerID int) int {
+	count := 0
+	query := repository.PetQuery{OwnerID: ownerID, Limit: 1000}
+	for {
+		pets, _ := s.PetRepository.FindPage(ctx, tx, query)
+		for _, p := range pets {
+			s.SearchEngine.Index(p)
+		}
+		count += len(pets)
+		if len(pets) < query.Limit {
+			return count
+		}
+		query.After = repository.PetKey(pets[len(pets)-1], nil)
+	}
+}
+
+func (s *PetServiceImpl) FindById(ctx context.Context, petID int, userID int) (_ web.PetResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PetService.FindById")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
 	if err != nil {
 		return web.PetResponse{}, err
 	}
-	defer helper.CommitOrRollback(tx)
+	defer helper.CommitOrRollback(tx, &err)
 
 	pet, err := s.PetRepository.FindById(ctx, tx, petID)
 	if err != nil {
@@ -72,15 +370,50 @@ func (s *PetServiceImpl) FindById(ctx context.Context, petID int, userID int) (w
 	if pet.CreatedBy != userID {
 		return web.PetResponse{}, fmt.Errorf("%w: not owner", errorsx.ErrUnauthorized)
 	}
-	return helper.ToPetResponse(pet), nil
+	resp := []web.PetResponse{helper.ToPetResponse(pet)}
+	s.withPhotos(ctx, tx, resp)
+	return resp[0], nil
+}
+
+func (s *PetServiceImpl) Update(ctx context.Context, req web.PetUpdateRequest, userID int) (_ web.PetResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PetService.Update")
+	defer span.End()
+
+	req.Microchip = normalizeMicrochip(req.Microchip)
+	if err := s.Validate.Struct(req); err != nil {
+		return web.PetResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.PetResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	pet, err := s.PetRepository.FindById(ctx, tx, req.Id)
+	if err != nil {
+		return web.PetResponse{}, fmt.Errorf("%w: pet not found", errorsx.ErrNotFound)
+	}
+	if pet.CreatedBy != userID {
+		return web.PetResponse{}, fmt.Errorf("%w: not owner", errorsx.ErrUnauthorized)
+	}
+	if !helper.IfMatch(req.IfMatch, pet.Version) {
+		return web.PetResponse{}, fmt.Errorf("%w: the pet is at version %d", errorsx.ErrPreconditionFailed, pet.Version)
+	}
+	return s.update(ctx, tx, pet, req)
 }
 
-func (s *PetServiceImpl) Update(ctx context.Context, req web.PetUpdateRequest, userID int) (web.PetResponse, error) {
-	tx, err := s.DB.Begin()
+// Patch applies a merge patch or JSON Patch to the pet's update request document, so only
+// the fields it mentions change, and validates the result like an Update.
+func (s *PetServiceImpl) Patch(ctx context.Context, req web.PatchRequest, userID int) (_ web.PetResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PetService.Patch")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
 	if err != nil {
 		return web.PetResponse{}, err
 	}
-	defer helper.CommitOrRollback(tx)
+	defer helper.CommitOrRollback(tx, &err)
 
 	pet, err := s.PetRepository.FindById(ctx, tx, req.Id)
 	if err != nil {
@@ -89,22 +422,64 @@ func (s *PetServiceImpl) Update(ctx context.Context, req web.PetUpdateRequest, u
 	if pet.CreatedBy != userID {
 		return web.PetResponse{}, fmt.Errorf("%w: not owner", errorsx.ErrUnauthorized)
 	}
+	if !helper.IfMatch(req.IfMatch, pet.Version) {
+		return web.PetResponse{}, fmt.Errorf("%w: the pet is at version %d", errorsx.ErrPreconditionFailed, pet.Version)
+	}
 
+	var update web.PetUpdateRequest
+	if err := applyPatch(helper.ToPetUpdateRequest(pet), req, &update); err != nil {
+		return web.PetResponse{}, err
+	}
+	if update.Id != pet.ID {
+		return web.PetResponse{}, fmt.Errorf("%w: id cannot be changed", errorsx.ErrValidation)
+	}
+	update.Microchip = normalizeMicrochip(update.Microchip)
+	if err := s.Validate.Struct(update); err != nil {
+		return web.PetResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+	return s.update(ctx, tx, pet, update)
+}
+
+// update saves pet, as read in tx, with the fields of a validated request.
+func (s *PetServiceImpl) update(ctx context.Context, tx *sql.Tx, pet domain.Pet, req web.PetUpdateRequest) (web.PetResponse, error) {
+	before := helper.ToPetResponse(pet)
+
+	species, breed, err := s.resolveTaxonomy(ctx, tx, req.Species, req.Breed)
+	if err != nil {
+		return web.PetResponse{}, err
+	}
 	pet.Name = req.Name
-	pet.Species = req.Species
+	pet.Species = species
+	pet.Breed = breed
 	pet.Price = req.Price
+	if err := s.applyProfile(ctx, tx, &pet, req.PetProfile); err != nil {
+		return web.PetResponse{}, err
+	}
 	pet.UpdatedAt = time.Now()
 
-	updated := s.PetRepository.Update(ctx, tx, pet)
-	return helper.ToPetResponse(updated), nil
+	updated, ok := s.PetRepository.Update(ctx, tx, pet)
+	if !ok {
+		return web.PetResponse{}, fmt.Errorf("%w: the pet was changed meanwhile", errorsx.ErrPreconditionFailed)
+	}
+	recordAudit(ctx, tx, s.AuditRepository, audit.ActionUpdate, audit.EntityPet, updated.ID, before, helper.ToPetResponse(updated))
+	s.SearchEngine.Index(updated)
+	logx.FromContext(ctx).Info("pet updated", "pet_id", updated.ID)
+	resp := []web.PetResponse{helper.ToPetResponse(updated)}
+	s.withPhotos(ctx, tx, resp)
+	return resp[0], nil
 }
 
-func (s *PetServiceImpl) Delete(ctx context.Context, petID int, userID int) error {
-	tx, err := s.DB.Begin()
+// Delete soft-deletes the pet. Its photos, rows and files, are kept until the pet is
+// purged, so a restore brings them back.
+func (s *PetServiceImpl) Delete(ctx context.Context, petID int, userID int, ifMatch string) (err error) {
+	ctx, span := tracing.Start(ctx, "PetService.Delete")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
 	if err != nil {
 		return err
 	}
-	defer helper.CommitOrRollback(tx)
+	defer helper.CommitOrRollback(tx, &err)
 
 	pet, err := s.PetRepository.FindById(ctx, tx, petID)
 	if err != nil {
@@ -113,7 +488,81 @@ func (s *PetServiceImpl) Delete(ctx context.Context, petID int, userID int) erro
 	if pet.CreatedBy != userID {
 		return fmt.Errorf("%w: not owner", errorsx.ErrUnauthorized)
 	}
+	if !helper.IfMatch(ifMatch, pet.Version) {
+		return fmt.Errorf("%w: the pet is at version %d", errorsx.ErrPreconditionFailed, pet.Version)
+	}
 
-	s.PetRepository.Delete(ctx, tx, petID)
+	before := helper.ToPetResponse(pet)
+	pet.DeletedAt = time.Now()
+	pet.Version++
+	s.PetRepository.Delete(ctx, tx, petID, pet.DeletedAt)
+	recordAudit(ctx, tx, s.AuditRepository, audit.ActionDelete, audit.EntityPet, petID, before, helper.ToPetResponse(pet))
+	s.SearchEngine.Remove(petID)
+	logx.FromContext(ctx).Info("pet deleted", "pet_id", petID)
 	return nil
 }
+
+func (s *PetServiceImpl) Restore(ctx context.Context, petID int) (_ web.PetResponse, err error) {
+	ctx, span := tracing.Start(ctx, "PetService.Restore")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.PetResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	pet, err := s.PetRepository.FindDeletedById(ctx, tx, petID)
+	if err != nil {
+		return web.PetResponse{}, fmt.Errorf("%w: no deleted pet %d", errorsx.ErrNotFound, petID)
+	}
+	if pet.Microchip != "" {
+		if other, err := s.PetRepository.FindByMicrochip(ctx, tx, pet.Microchip); err == nil {
+			return web.PetResponse{}, fmt.Errorf("%w: microchip is now registered to pet %d", errorsx.ErrConflict, other.ID)
+		}
+	}
+	if !s.PetRepository.Restore(ctx, tx, petID) {
+		return web.PetResponse{}, fmt.Errorf("%w: the owner is deleted; restore user %d first", errorsx.ErrConflict, pet.CreatedBy)
+	}
+
+	before := helper.ToPetResponse(pet)
+	pet.DeletedAt = time.Time{}
+	pet.Version++
+	recordAudit(ctx, tx, s.AuditRepository, audit.ActionRestore, audit.EntityPet, petID, before, helper.ToPetResponse(pet))
+	s.SearchEngine.Index(pet)
+	logx.FromContext(ctx).Info("pet restored", "pet_id", petID)
+	resp := []web.PetResponse{helper.ToPetResponse(pet)}
+	s.withPhotos(ctx, tx, resp)
+	return resp[0], nil
+}
+
+// ReassignOwner transfers all live pets of one user to another, e.g. before deleting an account.
+func (s *PetServiceImpl) ReassignOwner(ctx context.Context, fromUserID, toUserID int) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "PetService.ReassignOwner")
+	defer span.End()
+
+	if fromUserID == toUserID {
+		return 0, fmt.Errorf("%w: source and target owner are the same", errorsx.ErrValidation)
+	}
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return 0, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	// the lock keeps the new owner from being deleted before the pets are theirs
+	if _, err := s.UserRepository.Lock(ctx, tx, toUserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%w: user %d not found", errorsx.ErrNotFound, toUserID)
+		}
+		return 0, err
+	}
+	moved := s.PetRepository.ReassignOwner(ctx, tx, fromUserID, toUserID)
+	for _, petID := range moved {
+		recordAudit(ctx, tx, s.AuditRepository, audit.ActionUpdate, audit.EntityPet, petID,
+			map[string]int{"owner_id": fromUserID}, map[string]int{"owner_id": toUserID})
+	}
+	s.reindex(ctx, tx, toUserID)
+	logx.FromContext(ctx).Info("pets reassigned", "from_user_id", fromUserID, "to_user_id", toUserID, "count", len(moved))
+	return len(moved), nil
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// RetentionService hard-deletes what was soft-deleted long enough ago.
+type RetentionService interface {
+	// Purge removes the users and pets deleted before deletedBefore, with the photo files
+	// of those pets, and reports how many of each went.
+	Purge(ctx context.Context, deletedBefore time.Time) (PurgeResult, error)
+}
+
+type PurgeResult struct {
+	Users int `json:"users"`
+	Pets  int `json:"pets"`
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"Go-PetStoreApp/audit"
+	"Go-PetStoreApp/blob"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/logx"
+	"Go-PetStoreApp/repository"
+	"Go-PetStoreApp/tracing"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// purgeBatch bounds how many pets one purge transaction removes.
+const purgeBatch = 500
+
+type RetentionServiceImpl struct {
+	UserRepository     repository.UserRepository
+	PetRepository      repository.PetRepository
+	PetPhotoRepository repository.PetPhotoRepository
+	AuditRepository    repository.AuditRepository
+	BlobStore          blob.Store
+	DB                 *sql.DB
+}
+
+func NewRetentionService(users repository.UserRepository, pets repository.PetRepository, photos repository.PetPhotoRepository, audits repository.AuditRepository, store blob.Store, db *sql.DB) RetentionService {
+	return &RetentionServiceImpl{UserRepository: users, PetRepository: pets, PetPhotoRepository: photos, AuditRepository: audits, BlobStore: store, DB: db}
+}
+
+// Purge removes pets first, in batches, so that their photo files are known when their rows
+// go; the users are purged last, when none of their pets is left to block the delete.
+func (s *RetentionServiceImpl) Purge(ctx context.Context, deletedBefore time.Time) (PurgeResult, error) {
+	ctx, span := tracing.Start(ctx, "RetentionService.Purge")
+	defer span.End()
+
+	var result PurgeResult
+	for {
+		keys, purged, err := s.purgePets(ctx, deletedBefore)
+		if err != nil {
+			return result, err
+		}
+		result.Pets += purged
+		// the rows are gone, so a file that fails to delete is only wasted space
+		for _, key := range keys {
+			if err := s.BlobStore.Delete(ctx, key); err != nil {
+				logx.FromContext(ctx).Warn("deleting photo file", "key", key, "error", err)
+			}
+		}
+		if purged < purgeBatch {
+			break
+		}
+	}
+
+	users, err := s.purgeUsers(ctx, deletedBefore)
+	if err != nil {
+		return result, err
+	}
+	result.Users = users
+	if result.Pets > 0 || result.Users > 0 {
+		logx.FromContext(ctx).Info("deleted records purged", "users", result.Users, "pets", result.Pets, "deleted_before", deletedBefore)
+	}
+	return result, nil
+}
+
+// purgePets removes one batch of expired pets and returns their photo files' keys.
+func (s *RetentionServiceImpl) purgePets(ctx context.Context, deletedBefore time.Time) (_ []string, _ int, err error) {
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	ids := s.PetRepository.FindExpired(ctx, tx, deletedBefore, purgeBatch)
+	if len(ids) == 0 {
+		return nil, 0, nil
+	}
+	var keys []string
+	for _, photos := range s.PetPhotoRepository.FindByPets(ctx, tx, ids) {
+		for _, photo := range photos {
+			keys = append(keys, photo.OriginalKey, photo.MediumKey, photo.ThumbnailKey)
+		}
+	}
+	s.PetRepository.Purge(ctx, tx, ids)
+	for _, id := range ids {
+		recordAudit(ctx, tx, s.AuditRepository, audit.ActionPurge, audit.EntityPet, id, nil, nil)
+	}
+	return keys, len(ids), nil
+}
+
+func (s *RetentionServiceImpl) purgeUsers(ctx context.Context, deletedBefore time.Time) (_ int, err error) {
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return 0, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	ids, err := s.UserRepository.Purge(ctx, tx, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		recordAudit(ctx, tx, s.AuditRepository, audit.ActionPurge, audit.EntityUser, id, nil, nil)
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"Go-PetStoreApp/model/web"
+	"context"
+)
+
+// TaxonomyService manages the species and breeds catalog that pets must use.
+type TaxonomyService interface {
+	FindAllSpecies(ctx context.Context) ([]web.SpeciesResponse, error)
+	CreateSpecies(ctx context.Context, req web.SpeciesRequest) (web.SpeciesResponse, error)
+	UpdateSpecies(ctx context.Context, id int, req web.SpeciesRequest) (web.SpeciesResponse, error)
+	DeleteSpecies(ctx context.Context, id int) error
+
+	FindBreeds(ctx context.Context, speciesID int) ([]web.BreedResponse, error)
+	CreateBreed(ctx context.Context, speciesID int, req web.BreedRequest) (web.BreedResponse, error)
+	UpdateBreed(ctx context.Context, id int, req web.BreedRequest) (web.BreedResponse, error)
+	DeleteBreed(ctx context.Context, id int) error
+}
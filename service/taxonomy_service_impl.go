@@ -0,0 +1,248 @@
+package service
+
+import (
+	"Go-PetStoreApp/audit"
+	"Go-PetStoreApp/errorsx"
+	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/logx"
+	"Go-PetStoreApp/model/domain"
+	"Go-PetStoreApp/model/web"
+	"Go-PetStoreApp/repository"
+	"Go-PetStoreApp/tracing"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator"
+)
+
+type TaxonomyServiceImpl struct {
+	TaxonomyRepository repository.TaxonomyRepository
+	AuditRepository    repository.AuditRepository
+	DB                 *sql.DB
+	Validate           *validator.Validate
+}
+
+func NewTaxonomyService(repo repository.TaxonomyRepository, audits repository.AuditRepository, db *sql.DB, validate *validator.Validate) TaxonomyService {
+	return &TaxonomyServiceImpl{TaxonomyRepository: repo, AuditRepository: audits, DB: db, Validate: validate}
+}
+
+// catalogName trims and collapses whitespace so "Golden  Retriever " and "Golden Retriever"
+// are the same entry.
+func catalogName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+func (s *TaxonomyServiceImpl) FindAllSpecies(ctx context.Context) (_ []web.SpeciesResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TaxonomyService.FindAllSpecies")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	species := s.TaxonomyRepository.FindAllSpecies(ctx, tx)
+	res := make([]web.SpeciesResponse, 0, len(species))
+	for _, sp := range species {
+		res = append(res, helper.ToSpeciesResponse(sp))
+	}
+	return res, nil
+}
+
+// CreateSpecies stores the name in lower case, like the species normalized by the migration.
+func (s *TaxonomyServiceImpl) CreateSpecies(ctx context.Context, req web.SpeciesRequest) (_ web.SpeciesResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TaxonomyService.CreateSpecies")
+	defer span.End()
+
+	req.Name = strings.ToLower(catalogName(req.Name))
+	if err := s.Validate.Struct(req); err != nil {
+		return web.SpeciesResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.SpeciesResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	if _, err := s.TaxonomyRepository.FindSpeciesByName(ctx, tx, req.Name); err == nil {
+		return web.SpeciesResponse{}, fmt.Errorf("%w: species %q already exists", errorsx.ErrConflict, req.Name)
+	}
+	now := time.Now()
+	created := s.TaxonomyRepository.CreateSpecies(ctx, tx, domain.Species{Name: req.Name, CreatedAt: now, UpdatedAt: now})
+	logx.FromContext(ctx).Info("species created", "species_id", created.ID, "name", created.Name)
+	return helper.ToSpeciesResponse(created), nil
+}
+
+// UpdateSpecies renames a species; its pets and breeds are renamed with it.
+func (s *TaxonomyServiceImpl) UpdateSpecies(ctx context.Context, id int, req web.SpeciesRequest) (_ web.SpeciesResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TaxonomyService.UpdateSpecies")
+	defer span.End()
+
+	req.Name = strings.ToLower(catalogName(req.Name))
+	if err := s.Validate.Struct(req); err != nil {
+		return web.SpeciesResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.SpeciesResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	species, err := s.TaxonomyRepository.FindSpeciesById(ctx, tx, id)
+	if err != nil {
+		return web.SpeciesResponse{}, fmt.Errorf("%w: species not found", errorsx.ErrNotFound)
+	}
+	if other, err := s.TaxonomyRepository.FindSpeciesByName(ctx, tx, req.Name); err == nil && other.ID != id {
+		return web.SpeciesResponse{}, fmt.Errorf("%w: species %q already exists", errorsx.ErrConflict, req.Name)
+	}
+	previous := species.Name
+	pets := s.TaxonomyRepository.FindSpeciesPets(ctx, tx, previous)
+	species.Name = req.Name
+	species.UpdatedAt = time.Now()
+	updated := s.TaxonomyRepository.UpdateSpecies(ctx, tx, species)
+	// the foreign keys rename the pets' species too, which is a change to each of them
+	for _, petID := range pets {
+		recordAudit(ctx, tx, s.AuditRepository, audit.ActionUpdate, audit.EntityPet, petID,
+			map[string]string{"species": previous}, map[string]string{"species": updated.Name})
+	}
+	logx.FromContext(ctx).Info("species renamed", "species_id", id, "from", previous, "to", updated.Name)
+	return helper.ToSpeciesResponse(updated), nil
+}
+
+// DeleteSpecies refuses to delete a species that pets or breeds still use.
+func (s *TaxonomyServiceImpl) DeleteSpecies(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "TaxonomyService.DeleteSpecies")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	species, err := s.TaxonomyRepository.FindSpeciesById(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("%w: species not found", errorsx.ErrNotFound)
+	}
+	if pets, breeds := s.TaxonomyRepository.CountSpeciesUsage(ctx, tx, species.Name); pets > 0 || breeds > 0 {
+		return fmt.Errorf("%w: species %q is used by %d pets and %d breeds", errorsx.ErrConflict, species.Name, pets, breeds)
+	}
+	s.TaxonomyRepository.DeleteSpecies(ctx, tx, id)
+	logx.FromContext(ctx).Info("species deleted", "species_id", id, "name", species.Name)
+	return nil
+}
+
+func (s *TaxonomyServiceImpl) FindBreeds(ctx context.Context, speciesID int) (_ []web.BreedResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TaxonomyService.FindBreeds")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	species, err := s.TaxonomyRepository.FindSpeciesById(ctx, tx, speciesID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: species not found", errorsx.ErrNotFound)
+	}
+	breeds := s.TaxonomyRepository.FindBreedsBySpecies(ctx, tx, species.Name)
+	res := make([]web.BreedResponse, 0, len(breeds))
+	for _, b := range breeds {
+		res = append(res, helper.ToBreedResponse(b))
+	}
+	return res, nil
+}
+
+func (s *TaxonomyServiceImpl) CreateBreed(ctx context.Context, speciesID int, req web.BreedRequest) (_ web.BreedResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TaxonomyService.CreateBreed")
+	defer span.End()
+
+	req.Name = catalogName(req.Name)
+	if err := s.Validate.Struct(req); err != nil {
+		return web.BreedResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.BreedResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	species, err := s.TaxonomyRepository.FindSpeciesById(ctx, tx, speciesID)
+	if err != nil {
+		return web.BreedResponse{}, fmt.Errorf("%w: species not found", errorsx.ErrNotFound)
+	}
+	if _, err := s.TaxonomyRepository.FindBreedByName(ctx, tx, species.Name, req.Name); err == nil {
+		return web.BreedResponse{}, fmt.Errorf("%w: %s breed %q already exists", errorsx.ErrConflict, species.Name, req.Name)
+	}
+	now := time.Now()
+	created := s.TaxonomyRepository.CreateBreed(ctx, tx, domain.Breed{Species: species.Name, Name: req.Name, CreatedAt: now, UpdatedAt: now})
+	logx.FromContext(ctx).Info("breed created", "breed_id", created.ID, "species", created.Species, "name", created.Name)
+	return helper.ToBreedResponse(created), nil
+}
+
+// UpdateBreed renames a breed; pets of that breed are renamed with it.
+func (s *TaxonomyServiceImpl) UpdateBreed(ctx context.Context, id int, req web.BreedRequest) (_ web.BreedResponse, err error) {
+	ctx, span := tracing.Start(ctx, "TaxonomyService.UpdateBreed")
+	defer span.End()
+
+	req.Name = catalogName(req.Name)
+	if err := s.Validate.Struct(req); err != nil {
+		return web.BreedResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.BreedResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	breed, err := s.TaxonomyRepository.FindBreedById(ctx, tx, id)
+	if err != nil {
+		return web.BreedResponse{}, fmt.Errorf("%w: breed not found", errorsx.ErrNotFound)
+	}
+	if other, err := s.TaxonomyRepository.FindBreedByName(ctx, tx, breed.Species, req.Name); err == nil && other.ID != id {
+		return web.BreedResponse{}, fmt.Errorf("%w: %s breed %q already exists", errorsx.ErrConflict, breed.Species, req.Name)
+	}
+	previous := breed.Name
+	pets := s.TaxonomyRepository.FindBreedPets(ctx, tx, breed.Species, previous)
+	breed.Name = req.Name
+	breed.UpdatedAt = time.Now()
+	updated := s.TaxonomyRepository.UpdateBreed(ctx, tx, breed)
+	for _, petID := range pets {
+		recordAudit(ctx, tx, s.AuditRepository, audit.ActionUpdate, audit.EntityPet, petID,
+			map[string]string{"breed": previous}, map[string]string{"breed": updated.Name})
+	}
+	logx.FromContext(ctx).Info("breed renamed", "breed_id", id, "from", previous, "to", updated.Name)
+	return helper.ToBreedResponse(updated), nil
+}
+
+// DeleteBreed refuses to delete a breed that pets still use.
+func (s *TaxonomyServiceImpl) DeleteBreed(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "TaxonomyService.DeleteBreed")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	breed, err := s.TaxonomyRepository.FindBreedById(ctx, tx, id)
+	if err != nil {
+		return fmt.Errorf("%w: breed not found", errorsx.ErrNotFound)
+	}
+	if pets := s.TaxonomyRepository.CountBreedUsage(ctx, tx, breed.Species, breed.Name); pets > 0 {
+		return fmt.Errorf("%w: breed %q is used by %d pets", errorsx.ErrConflict, breed.Name, pets)
+	}
+	s.TaxonomyRepository.DeleteBreed(ctx, tx, id)
+	logx.FromContext(ctx).Info("breed deleted", "breed_id", id, "name", breed.Name)
+	return nil
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"Go-PetStoreApp/model/web"
 	"context"
+	"time"
 )
 
 type UserService interface {
@@ -12,6 +13,18 @@ type UserService interface {
 	FindById(ctx context.Context, id int) (web.UserResponse, error)
 	FindAll(ctx context.Context) ([]web.UserResponse, error)
 	Update(ctx context.Context, id int, req web.UserUpdateRequest) (web.UserResponse, error)
+	// Patch changes only the fields a merge patch or JSON Patch mentions; see web.PatchRequest.
+	Patch(ctx context.Context, req web.PatchRequest) (web.UserResponse, error)
 	ChangePassword(ctx context.Context, req web.UserChangePasswordRequest) error
-	Delete(ctx context.Context, id int) error
+	// Delete fails if ifMatch (an If-Match header) names another version than the current one.
+	Delete(ctx context.Context, id int, ifMatch string) error
+	// FindDeleted and Restore are administrative: soft-deleted users and undoing a delete.
+	FindDeleted(ctx context.Context) ([]web.UserResponse, error)
+	Restore(ctx context.Context, id int) (web.UserResponse, error)
+	// SetRole, ResetPassword and RevokeTokens are administrative; the first two also revoke
+	// the user's tokens so the change takes effect immediately.
+	SetRole(ctx context.Context, id int, req web.UserSetRoleRequest) (web.UserResponse, error)
+	ResetPassword(ctx context.Context, id int, req web.UserResetPasswordRequest) error
+	RevokeTokens(ctx context.Context, id int) error
+	TokenRevoked(ctx context.Context, id int, issuedAt time.Time) (bool, error)
 }
\ No newline at end of file
@@ -1,11 +1,16 @@
 package service
 
 import (
+	"Go-PetStoreApp/audit"
 	"Go-PetStoreApp/errorsx"
 	"Go-PetStoreApp/helper"
+	"Go-PetStoreApp/logx"
+	"Go-PetStoreApp/metrics"
 	"Go-PetStoreApp/model/domain"
 	"Go-PetStoreApp/model/web"
 	"Go-PetStoreApp/repository"
+	"Go-PetStoreApp/search"
+	"Go-PetStoreApp/tracing"
 	"context"
 	"database/sql"
 	"errors"
@@ -17,33 +22,48 @@ import (
 )
 
 type UserServiceImpl struct {
-	UserRepository repository.UserRepository
-	DB             *sql.DB
-	Validate       *validator.Validate
-	TokenExpiry    int
+	UserRepository  repository.UserRepository
+	PetRepository   repository.PetRepository
+	AuditRepository repository.AuditRepository
+	DB              *sql.DB
+	Validate        *validator.Validate
+	JWT             *helper.JWT
+	SearchEngine    search.Engine
 }
 
-func NewUserService(userRepository repository.UserRepository, DB *sql.DB, validate *validator.Validate, tokenExpiry int) UserService {
+// Password changes are audited without the hashes: only that the password changed.
+var (
+	passwordUnchanged = map[string]any{}
+	passwordChanged   = map[string]any{"password": "changed"}
+)
+
+func NewUserService(userRepository repository.UserRepository, petRepository repository.PetRepository, auditRepository repository.AuditRepository, DB *sql.DB, validate *validator.Validate, jwt *helper.JWT, engine search.Engine) UserService {
 	return &UserServiceImpl{
-		UserRepository: userRepository,
-		DB:             DB,
-		Validate:       validate,
-		TokenExpiry:    tokenExpiry,
+		UserRepository:  userRepository,
+		PetRepository:   petRepository,
+		AuditRepository: auditRepository,
+		DB:              DB,
+		Validate:        validate,
+		JWT:             jwt,
+		SearchEngine:    engine,
 	}
 }
 
-func (s *UserServiceImpl) Register(ctx context.Context, request web.UserRegisterRequest) (web.AuthResponse, error) {
+func (s *UserServiceImpl) Register(ctx context.Context, request web.UserRegisterRequest) (_ web.AuthResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.Register")
+	defer span.End()
+
 	// validate request
 	if err := s.Validate.Struct(request); err != nil {
 		return web.AuthResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
 	}
 
 	// start transaction
-	tx, err := s.DB.Begin()
+	tx, err := helper.BeginTx(ctx, s.DB)
 	if err != nil {
 		return web.AuthResponse{}, err
 	}
-	defer helper.CommitOrRollback(tx)
+	defer helper.CommitOrRollback(tx, &err)
 
 	// set role (default: user)
 	role := "user"
@@ -82,11 +102,15 @@ func (s *UserServiceImpl) Register(ctx context.Context, request web.UserRegister
 		return web.AuthResponse{}, err
 	}
 
+	recordAudit(ctx, tx, s.AuditRepository, audit.ActionCreate, audit.EntityUser, createdUser.ID, nil, helper.ToUserResponse(createdUser))
+
 	// generate JWT token
-	token, err := helper.GenerateToken(createdUser.ID, createdUser.Email, createdUser.Username, createdUser.Role, s.TokenExpiry)
+	token, err := s.JWT.GenerateToken(createdUser.ID, createdUser.Email, createdUser.Username, createdUser.Role)
 	if err != nil {
 		return web.AuthResponse{}, err
 	}
+	metrics.UserRegistrations.Inc()
+	logx.FromContext(ctx).Info("user registered", "new_user_id", createdUser.ID, "new_user_role", createdUser.Role)
 
 	return web.AuthResponse{
 		Token: token,
@@ -95,61 +119,81 @@ func (s *UserServiceImpl) Register(ctx context.Context, request web.UserRegister
 }
 
 
-func (s *UserServiceImpl) Login(ctx context.Context, request web.UserLoginRequest) (web.AuthResponse, error) {
+func (s *UserServiceImpl) Login(ctx context.Context, request web.UserLoginRequest) (_ web.AuthResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.Login")
+	defer span.End()
+
 	if err := s.Validate.Struct(request); err != nil {
 		return web.AuthResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
 	}
 
-	tx, err := s.DB.Begin()
+	tx, err := helper.BeginTx(ctx, s.DB)
 	if err != nil {
 		return web.AuthResponse{}, err
 	}
-	defer helper.CommitOrRollback(tx)
+	defer helper.CommitOrRollback(tx, &err)
 
 	user, err := s.UserRepository.FindByUsername(ctx, tx, request.Username)
 	if err != nil {
+		metrics.LoginsFailed.Inc()
+		logx.FromContext(ctx).Info("login failed", "username", request.Username, "reason", "unknown user")
 		return web.AuthResponse{}, fmt.Errorf("%w: invalid username or password", errorsx.ErrUnauthorized)
 	}
 	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password)) != nil {
+		metrics.LoginsFailed.Inc()
+		logx.FromContext(ctx).Info("login failed", "username", request.Username, "reason", "bad password")
 		return web.AuthResponse{}, fmt.Errorf("%w: invalid username or password", errorsx.ErrUnauthorized)
 	}
 
-	token, err := helper.GenerateToken(user.ID, user.Email, user.Username, user.Role, s.TokenExpiry)
+	token, err := s.JWT.GenerateToken(user.ID, user.Email, user.Username, user.Role)
 	if err != nil {
 		return web.AuthResponse{}, err
 	}
 	return web.AuthResponse{Token: token, User: helper.ToUserResponse(user)}, nil
 }
 
-func (s *UserServiceImpl) RefreshToken(ctx context.Context, oldToken string) (web.AuthResponse, error) {
-	claims, err := helper.ValidateToken(oldToken)
+func (s *UserServiceImpl) RefreshToken(ctx context.Context, oldToken string) (_ web.AuthResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.RefreshToken")
+	defer span.End()
+
+	claims, err := s.JWT.ValidateToken(oldToken)
 	if err != nil {
 		return web.AuthResponse{}, err
 	}
-	// create new token
-	newToken, err := helper.GenerateToken(claims.UserID, claims.Email, claims.Username, claims.Role, s.TokenExpiry)
+	tx, err := helper.BeginTx(ctx, s.DB)
 	if err != nil {
 		return web.AuthResponse{}, err
 	}
-	// fetch user for response
-	tx, err := s.DB.Begin()
+	defer helper.CommitOrRollback(tx, &err)
+
+	before, err := s.UserRepository.TokensRevokedBefore(ctx, tx, claims.UserID)
 	if err != nil {
 		return web.AuthResponse{}, err
 	}
-	defer helper.CommitOrRollback(tx)
+	if !before.IsZero() && !claims.IssuedAt.After(before.Truncate(time.Second)) {
+		return web.AuthResponse{}, fmt.Errorf("%w: token revoked", errorsx.ErrUnauthorized)
+	}
+	// fetch user so the new token carries the current role
 	u, err := s.UserRepository.FindById(ctx, tx, claims.UserID)
 	if err != nil {
 		return web.AuthResponse{}, err
 	}
+	newToken, err := s.JWT.GenerateToken(u.ID, u.Email, u.Username, u.Role)
+	if err != nil {
+		return web.AuthResponse{}, err
+	}
 	return web.AuthResponse{Token: newToken, User: helper.ToUserResponse(u)}, nil
 }
 
-func (s *UserServiceImpl) FindById(ctx context.Context, id int) (web.UserResponse, error) {
-	tx, err := s.DB.Begin()
+func (s *UserServiceImpl) FindById(ctx context.Context, id int) (_ web.UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.FindById")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
 	if err != nil {
 		return web.UserResponse{}, err
 	}
-	defer helper.CommitOrRollback(tx)
+	defer helper.CommitOrRollback(tx, &err)
 
 	user, err := s.UserRepository.FindById(ctx, tx, id)
 	if err != nil {
@@ -163,12 +207,15 @@ func (s *UserServiceImpl) FindById(ctx context.Context, id int) (web.UserRespons
 }
 
 
-func (s *UserServiceImpl) FindAll(ctx context.Context) ([]web.UserResponse, error) {
-	tx, err := s.DB.Begin()
+func (s *UserServiceImpl) FindAll(ctx context.Context) (_ []web.UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.FindAll")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
 	if err != nil {
 		return nil, err
 	}
-	defer helper.CommitOrRollback(tx)
+	defer helper.CommitOrRollback(tx, &err)
 
 	users, err := s.UserRepository.FindAll(ctx, tx)
 	if err != nil {
@@ -183,16 +230,19 @@ func (s *UserServiceImpl) FindAll(ctx context.Context) ([]web.UserResponse, erro
 }
 
 
-func (s *UserServiceImpl) Update(ctx context.Context, id int, request web.UserUpdateRequest) (web.UserResponse, error) {
+func (s *UserServiceImpl) Update(ctx context.Context, id int, request web.UserUpdateRequest) (_ web.UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.Update")
+	defer span.End()
+
 	if err := s.Validate.Struct(request); err != nil {
 		return web.UserResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
 	}
 
-	tx, err := s.DB.Begin()
+	tx, err := helper.BeginTx(ctx, s.DB)
 	if err != nil {
 		return web.UserResponse{}, err
 	}
-	defer helper.CommitOrRollback(tx)
+	defer helper.CommitOrRollback(tx, &err)
 
 	// Find user first
 	user, err := s.UserRepository.FindById(ctx, tx, id)
@@ -202,6 +252,51 @@ func (s *UserServiceImpl) Update(ctx context.Context, id int, request web.UserUp
 		}
 		return web.UserResponse{}, err
 	}
+	if !helper.IfMatch(request.IfMatch, user.Version) {
+		return web.UserResponse{}, fmt.Errorf("%w: the user is at version %d", errorsx.ErrPreconditionFailed, user.Version)
+	}
+	return s.update(ctx, tx, user, request)
+}
+
+// Patch applies a merge patch or JSON Patch to the user's update request document and
+// validates the result like an Update.
+func (s *UserServiceImpl) Patch(ctx context.Context, request web.PatchRequest) (_ web.UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.Patch")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.UserResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	user, err := s.UserRepository.FindById(ctx, tx, request.Id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.UserResponse{}, fmt.Errorf("%w: user not found", errorsx.ErrNotFound)
+		}
+		return web.UserResponse{}, err
+	}
+	if !helper.IfMatch(request.IfMatch, user.Version) {
+		return web.UserResponse{}, fmt.Errorf("%w: the user is at version %d", errorsx.ErrPreconditionFailed, user.Version)
+	}
+
+	var update web.UserUpdateRequest
+	if err := applyPatch(helper.ToUserUpdateRequest(user), request, &update); err != nil {
+		return web.UserResponse{}, err
+	}
+	if update.Id != user.ID {
+		return web.UserResponse{}, fmt.Errorf("%w: id cannot be changed", errorsx.ErrValidation)
+	}
+	if err := s.Validate.Struct(update); err != nil {
+		return web.UserResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+	return s.update(ctx, tx, user, update)
+}
+
+// update saves user, as read in tx, with the fields of a validated request.
+func (s *UserServiceImpl) update(ctx context.Context, tx *sql.Tx, user domain.User, request web.UserUpdateRequest) (web.UserResponse, error) {
+	before := helper.ToUserResponse(user)
 
 	// Update fields
 	user.Username = request.Username
@@ -209,19 +304,30 @@ func (s *UserServiceImpl) Update(ctx context.Context, id int, request web.UserUp
 	user.UpdatedAt = time.Now()
 
 	updatedUser, err := s.UserRepository.Update(ctx, tx, user)
+	if errors.Is(err, sql.ErrNoRows) {
+		return web.UserResponse{}, fmt.Errorf("%w: the user was changed meanwhile", errorsx.ErrPreconditionFailed)
+	}
 	if err != nil {
 		return web.UserResponse{}, err
 	}
+	recordAudit(ctx, tx, s.AuditRepository, audit.ActionUpdate, audit.EntityUser, user.ID, before, helper.ToUserResponse(updatedUser))
 
 	return helper.ToUserResponse(updatedUser), nil
 }
 
-func (s *UserServiceImpl) ChangePassword(ctx context.Context, req web.UserChangePasswordRequest) error {
-    tx, err := s.DB.Begin()
+func (s *UserServiceImpl) ChangePassword(ctx context.Context, req web.UserChangePasswordRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.ChangePassword")
+	defer span.End()
+
+    if err := s.Validate.Struct(req); err != nil {
+        return fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+    }
+
+    tx, err := helper.BeginTx(ctx, s.DB)
     if err != nil {
         return err
     }
-    defer helper.CommitOrRollback(tx)
+    defer helper.CommitOrRollback(tx, &err)
 
     user, err := s.UserRepository.FindById(ctx, tx, req.Id)
     if err != nil {
@@ -230,9 +336,13 @@ func (s *UserServiceImpl) ChangePassword(ctx context.Context, req web.UserChange
         }
         return err
     }
+    if !helper.IfMatch(req.IfMatch, user.Version) {
+        return fmt.Errorf("%w: the user is at version %d", errorsx.ErrPreconditionFailed, user.Version)
+    }
 
     // compare old password
     if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.OldPassword)); err != nil {
+        logx.FromContext(ctx).Info("password change rejected", "reason", "bad old password")
         return fmt.Errorf("%w: invalid old password", errorsx.ErrUnauthorized)
     }
 
@@ -242,33 +352,246 @@ func (s *UserServiceImpl) ChangePassword(ctx context.Context, req web.UserChange
         return err
     }
 
-    user.PasswordHash = string(hashed)
-    user.UpdatedAt = time.Now()
-
-    _, err = s.UserRepository.Update(ctx, tx, user)
-    return err
+    err = s.UserRepository.UpdatePassword(ctx, tx, user.ID, user.Version, string(hashed))
+    if errors.Is(err, sql.ErrNoRows) {
+        return fmt.Errorf("%w: the user was changed meanwhile", errorsx.ErrPreconditionFailed)
+    }
+    if err != nil {
+        return err
+    }
+    recordAudit(ctx, tx, s.AuditRepository, audit.ActionUpdate, audit.EntityUser, user.ID, passwordUnchanged, passwordChanged)
+    return nil
 }
 
-func (s *UserServiceImpl) Delete(ctx context.Context, id int) error {
-	tx, err := s.DB.Begin()
+// Delete soft-deletes the user together with their pets, all with the same deleted_at, and
+// revokes the user's tokens.
+func (s *UserServiceImpl) Delete(ctx context.Context, id int, ifMatch string) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.Delete")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
 	if err != nil {
 		return err
 	}
-	defer helper.CommitOrRollback(tx)
+	defer helper.CommitOrRollback(tx, &err)
 
 	// Ensure user exists
-	_, err = s.UserRepository.FindById(ctx, tx, id)
+	user, err := s.UserRepository.FindById(ctx, tx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return fmt.Errorf("%w: user not found", errorsx.ErrNotFound)
 		}
 		return err
 	}
+	if !helper.IfMatch(ifMatch, user.Version) {
+		return fmt.Errorf("%w: the user is at version %d", errorsx.ErrPreconditionFailed, user.Version)
+	}
 
-	// Delete using repository
-	if err := s.UserRepository.Delete(ctx, tx, id); err != nil {
+	now := time.Now()
+	if err := s.UserRepository.Delete(ctx, tx, id, now); err != nil {
+		return err
+	}
+	before := helper.ToUserResponse(user)
+	user.DeletedAt = now
+	user.Version++
+	recordAudit(ctx, tx, s.AuditRepository, audit.ActionDelete, audit.EntityUser, id, before, helper.ToUserResponse(user))
+	pets := s.PetRepository.DeleteByOwner(ctx, tx, id, now)
+	for _, petID := range pets {
+		recordAudit(ctx, tx, s.AuditRepository, audit.ActionDelete, audit.EntityPet, petID,
+			map[string]any{"deleted_at": nil}, map[string]any{"deleted_at": now})
+		s.SearchEngine.Remove(petID)
+	}
+	if err := s.UserRepository.RevokeTokens(ctx, tx, id, now); err != nil {
 		return err
 	}
+	logx.FromContext(ctx).Info("user deleted", "deleted_user_id", id, "pets", len(pets))
 
 	return nil
 }
+
+func (s *UserServiceImpl) FindDeleted(ctx context.Context) (_ []web.UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.FindDeleted")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return nil, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	users, err := s.UserRepository.FindDeleted(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]web.UserResponse, 0, len(users))
+	for _, u := range users {
+		res = append(res, helper.ToUserResponse(u))
+	}
+	return res, nil
+}
+
+// Restore undeletes a user and the pets that were deleted with them. The user's old tokens
+// stay revoked, so they log in again.
+func (s *UserServiceImpl) Restore(ctx context.Context, id int) (_ web.UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.Restore")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.UserResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	user, err := s.UserRepository.FindDeletedById(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.UserResponse{}, fmt.Errorf("%w: no deleted user %d", errorsx.ErrNotFound, id)
+		}
+		return web.UserResponse{}, err
+	}
+	// names are only reserved by live users, so they may have been taken since
+	if u, _ := s.UserRepository.FindByEmail(ctx, tx, user.Email); u.ID != 0 {
+		return web.UserResponse{}, fmt.Errorf("%w: email is now registered to user %d", errorsx.ErrConflict, u.ID)
+	}
+	if u, _ := s.UserRepository.FindByUsername(ctx, tx, user.Username); u.ID != 0 {
+		return web.UserResponse{}, fmt.Errorf("%w: username is now taken by user %d", errorsx.ErrConflict, u.ID)
+	}
+	if err := s.UserRepository.Restore(ctx, tx, id); err != nil {
+		return web.UserResponse{}, err
+	}
+	before := helper.ToUserResponse(user)
+	deletedAt := user.DeletedAt
+	user.DeletedAt = time.Time{}
+	user.Version++
+	recordAudit(ctx, tx, s.AuditRepository, audit.ActionRestore, audit.EntityUser, id, before, helper.ToUserResponse(user))
+	pets := s.PetRepository.RestoreByOwner(ctx, tx, id, deletedAt)
+	for _, petID := range pets {
+		pet, err := s.PetRepository.FindById(ctx, tx, petID)
+		if err != nil {
+			return web.UserResponse{}, err
+		}
+		recordAudit(ctx, tx, s.AuditRepository, audit.ActionRestore, audit.EntityPet, petID,
+			map[string]any{"deleted_at": deletedAt}, map[string]any{"deleted_at": nil})
+		s.SearchEngine.Index(pet)
+	}
+	logx.FromContext(ctx).Info("user restored", "target_user_id", id, "pets", len(pets))
+
+	return helper.ToUserResponse(user), nil
+}
+
+func (s *UserServiceImpl) SetRole(ctx context.Context, id int, req web.UserSetRoleRequest) (_ web.UserResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.SetRole")
+	defer span.End()
+
+	if err := s.Validate.Struct(req); err != nil {
+		return web.UserResponse{}, fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return web.UserResponse{}, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	user, err := s.UserRepository.FindById(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.UserResponse{}, fmt.Errorf("%w: user not found", errorsx.ErrNotFound)
+		}
+		return web.UserResponse{}, err
+	}
+	err = s.UserRepository.UpdateRole(ctx, tx, id, user.Version, req.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return web.UserResponse{}, fmt.Errorf("%w: the user was changed meanwhile", errorsx.ErrPreconditionFailed)
+	}
+	if err != nil {
+		return web.UserResponse{}, err
+	}
+	// tokens carry the role, so the old ones must go
+	if err := s.UserRepository.RevokeTokens(ctx, tx, id, time.Now()); err != nil {
+		return web.UserResponse{}, err
+	}
+	logx.FromContext(ctx).Info("user role changed", "target_user_id", id, "from", user.Role, "to", req.Role)
+
+	before := helper.ToUserResponse(user)
+	user.Role = req.Role
+	user.Version++
+	recordAudit(ctx, tx, s.AuditRepository, audit.ActionUpdate, audit.EntityUser, id, before, helper.ToUserResponse(user))
+	return helper.ToUserResponse(user), nil
+}
+
+func (s *UserServiceImpl) ResetPassword(ctx context.Context, id int, req web.UserResetPasswordRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.ResetPassword")
+	defer span.End()
+
+	if err := s.Validate.Struct(req); err != nil {
+		return fmt.Errorf("%w: %v", errorsx.ErrValidation, err)
+	}
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	user, err := s.UserRepository.FindById(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: user not found", errorsx.ErrNotFound)
+		}
+		return err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	err = s.UserRepository.UpdatePassword(ctx, tx, id, user.Version, string(hashed))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: the user was changed meanwhile", errorsx.ErrPreconditionFailed)
+	}
+	if err != nil {
+		return err
+	}
+	recordAudit(ctx, tx, s.AuditRepository, audit.ActionUpdate, audit.EntityUser, id, passwordUnchanged, passwordChanged)
+	logx.FromContext(ctx).Info("user password reset", "target_user_id", id)
+	return s.UserRepository.RevokeTokens(ctx, tx, id, time.Now())
+}
+
+func (s *UserServiceImpl) RevokeTokens(ctx context.Context, id int) (err error) {
+	ctx, span := tracing.Start(ctx, "UserService.RevokeTokens")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	if _, err := s.UserRepository.FindById(ctx, tx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: user not found", errorsx.ErrNotFound)
+		}
+		return err
+	}
+	logx.FromContext(ctx).Info("user tokens revoked", "target_user_id", id)
+	return s.UserRepository.RevokeTokens(ctx, tx, id, time.Now())
+}
+
+// TokenRevoked reports whether a token issued at issuedAt was revoked. Token times have
+// second precision, so a token issued in the same second as the revocation counts as revoked.
+func (s *UserServiceImpl) TokenRevoked(ctx context.Context, id int, issuedAt time.Time) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.TokenRevoked")
+	defer span.End()
+
+	tx, err := helper.BeginTx(ctx, s.DB)
+	if err != nil {
+		return false, err
+	}
+	defer helper.CommitOrRollback(tx, &err)
+
+	before, err := s.UserRepository.TokensRevokedBefore(ctx, tx, id)
+	if err != nil || before.IsZero() {
+		return false, err
+	}
+	return !issuedAt.After(before.Truncate(time.Second)), nil
+}
@@ -0,0 +1,136 @@
+// Package tracing configures OpenTelemetry for the service. Spans are started with Start
+// (services, middleware) and StartQuery (repositories). With the "none" exporter new traces
+// are not sampled but still get IDs, so they propagate and show up in logs.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ServiceName       = "petstore"
+	instrumentationID = "Go-PetStoreApp"
+)
+
+type Config struct {
+	// Exporter is "none", "stdout" or "otlp".
+	Exporter string
+	// File receives spans from the stdout exporter (one JSON document per span); empty means stdout.
+	File string
+	// Endpoint is the OTLP/HTTP collector, e.g. "localhost:4318". Empty falls back to the
+	// standard OTEL_EXPORTER_OTLP_* environment variables.
+	Endpoint string
+	Insecure bool
+	// SampleRatio is the fraction of new traces recorded; inbound sampled parents are always honoured.
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider and the W3C trace-context/baggage propagator.
+// The returned function flushes buffered spans and must be called before exit.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var closer io.Closer
+	sampler := sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))
+	switch strings.ToLower(cfg.Exporter) {
+	case "", "none":
+		sampler = sdktrace.ParentBased(sdktrace.NeverSample())
+	case "stdout":
+		var w io.Writer = os.Stdout
+		if cfg.File != "" {
+			f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return nil, fmt.Errorf("tracing: opening %s: %w", cfg.File, err)
+			}
+			w, closer = f, f
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, err
+		}
+		exporter = exp
+	case "otlp":
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exp, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		exporter = exp
+	default:
+		return nil, fmt.Errorf("tracing: unknown exporter %q", cfg.Exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, err
+	}
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res), sdktrace.WithSampler(sampler)}
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if cerr := closer.Close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
+
+// Start begins an internal span named e.g. "PetService.Create".
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationID).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartQuery begins a client span for one SQL statement.
+func StartQuery(ctx context.Context, name, statement string) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationID).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBQueryText(statement)),
+	)
+}
+
+// Fail marks span as failed. sql.ErrNoRows and other expected outcomes should not be passed here.
+func Fail(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// IDs returns the trace and span ID of the span in ctx, or empty strings when there is none.
+func IDs(ctx context.Context) (traceID, spanID string) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", ""
+	}
+	return sc.TraceID().String(), sc.SpanID().String()
+}